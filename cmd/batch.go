@@ -0,0 +1,418 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/normalize"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagBatchColumn       string
+	flagBatchName         string
+	flagBatchWait         bool
+	flagBatchPollInterval time.Duration
+	flagBatchOutput       string
+	flagBatchFile         string
+	flagBatchJSON         bool
+)
+
+func init() {
+	batchUploadCmd.Flags().StringVarP(&flagBatchColumn, "column", "c", "", "Name of the email column in the CSV")
+	batchUploadCmd.Flags().StringVar(&flagBatchName, "name", "", "Name for the batch (default: the file name)")
+	batchUploadCmd.Flags().BoolVar(&flagBatchWait, "wait", false, "Wait for the batch to finish and download the results")
+	batchUploadCmd.Flags().DurationVar(&flagBatchPollInterval, "poll-interval", 5*time.Second, "How often to check batch progress with --wait")
+	batchUploadCmd.Flags().StringVarP(&flagBatchOutput, "output", "o", "", "Output file path with --wait (default: <input>_validated.csv)")
+
+	batchStatusCmd.Flags().BoolVar(&flagBatchJSON, "json", false, "Output status as JSON")
+	batchStatusCmd.Flags().BoolVar(&flagBatchWait, "wait", false, "Poll until the batch finishes")
+	batchStatusCmd.Flags().DurationVar(&flagBatchPollInterval, "poll-interval", 5*time.Second, "How often to check batch progress with --wait")
+
+	batchDownloadCmd.Flags().StringVarP(&flagBatchFile, "file", "f", "", "The CSV file that was uploaded (required)")
+	batchDownloadCmd.Flags().StringVarP(&flagBatchColumn, "column", "c", "", "Name of the email column in the CSV")
+	batchDownloadCmd.Flags().StringVarP(&flagBatchOutput, "output", "o", "", "Output file path (default: <file>_validated.csv)")
+
+	batchCmd.AddCommand(batchUploadCmd, batchStatusCmd, batchDownloadCmd, batchCancelCmd)
+	rootCmd.AddCommand(batchCmd)
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Run bulk validation jobs on the Truelist servers",
+	Long: `Upload a CSV as a server-side bulk job instead of validating it row by row.
+The job keeps running after the CLI exits; check on it later with
+"truelist batch status" and fetch results with "truelist batch download".
+
+Example:
+  truelist batch upload contacts.csv
+  truelist batch status <batch-id>
+  truelist batch download <batch-id> --file contacts.csv
+
+  # Upload, wait, and download in one step:
+  truelist batch upload contacts.csv --wait`,
+}
+
+var batchUploadCmd = &cobra.Command{
+	Use:   "upload <file.csv>",
+	Short: "Upload a CSV file as a bulk validation job",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+
+		if err := checkPollInterval(); err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+		c, err := newClient()
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		emails, err := extractEmailCSV(path, flagBatchColumn)
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		name := flagBatchName
+		if name == "" {
+			name = filepath.Base(path)
+		}
+
+		batch, err := c.CreateBatch(cmd.Context(), name, emails)
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		if !flagBatchWait {
+			fmt.Printf("Batch created: %s\n", batch.ID)
+			fmt.Fprintf(os.Stderr, "Check progress with: truelist batch status %s\n", batch.ID)
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "Batch created: %s\n", batch.ID)
+
+		// waitForBatch returns no batch when interrupted, so keep the ID.
+		id := batch.ID
+		if _, err := waitForBatch(cmd.Context(), c, id, flagBatchPollInterval); err != nil {
+			output.PrintError(os.Stderr, err)
+			if errors.Is(err, errInterrupted) {
+				fmt.Fprintf(os.Stderr, "The batch keeps running on the server; check it with: truelist batch status %s\n", id)
+			}
+			return err
+		}
+
+		outPath := flagBatchOutput
+		if outPath == "" {
+			outPath = defaultOutputPath(path)
+		}
+		if err := downloadBatchCSV(cmd.Context(), c, id, path, flagBatchColumn, outPath); err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+		return nil
+	},
+}
+
+var batchStatusCmd = &cobra.Command{
+	Use:   "status <batch-id>",
+	Short: "Show the status and progress of a bulk job",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkPollInterval(); err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+		c, err := newClient()
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		var batch *client.Batch
+		if flagBatchWait {
+			batch, err = waitForBatch(cmd.Context(), c, args[0], flagBatchPollInterval)
+		} else {
+			batch, err = c.GetBatch(cmd.Context(), args[0])
+		}
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		if flagBatchJSON {
+			return output.PrintBatchJSON(os.Stdout, batch)
+		}
+		output.PrintBatch(os.Stdout, batch)
+		return nil
+	},
+}
+
+var batchDownloadCmd = &cobra.Command{
+	Use:   "download <batch-id>",
+	Short: "Download the results of a finished bulk job",
+	Long: `Download the results of a finished bulk job and merge them into the
+original CSV, producing the same columns as "truelist validate --file".`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagBatchFile == "" {
+			err := usageErrorf("--file is required to merge results into the original CSV")
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		c, err := newClient()
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		outPath := flagBatchOutput
+		if outPath == "" {
+			outPath = defaultOutputPath(flagBatchFile)
+		}
+		if err := downloadBatchCSV(cmd.Context(), c, args[0], flagBatchFile, flagBatchColumn, outPath); err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+		return nil
+	},
+}
+
+var batchCancelCmd = &cobra.Command{
+	Use:   "cancel <batch-id>",
+	Short: "Cancel a bulk job that has not finished",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		batch, err := c.CancelBatch(cmd.Context(), args[0])
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		output.PrintBatch(os.Stdout, batch)
+		return nil
+	},
+}
+
+// extractEmailCSV reads the email column of a CSV file into a new
+// single-column CSV suitable for CreateBatch. Blank emails are skipped.
+func extractEmailCSV(path, column string) (io.Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read CSV header: %w", err)
+	}
+
+	emailColIdx, err := emailColumn(header, column)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write([]string{"email"})
+
+	count := 0
+	for {
+		row, readErr := reader.Read()
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("error reading CSV: %w", readErr)
+		}
+		if emailColIdx >= len(row) {
+			continue
+		}
+		if email := normalize.Address(row[emailColIdx], normalize.Options{}); email != "" {
+			_ = writer.Write([]string{email})
+			count++
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("no emails found in %s", path)
+	}
+	return &buf, nil
+}
+
+// checkPollInterval rejects a --poll-interval that can't be waited on.
+func checkPollInterval() error {
+	if flagBatchPollInterval <= 0 {
+		return usageErrorf("--poll-interval must be greater than 0, not %s", flagBatchPollInterval)
+	}
+	return nil
+}
+
+// waitForBatch polls a bulk job until it finishes, showing a progress bar.
+// It returns an error if the job fails or is cancelled.
+func waitForBatch(ctx context.Context, c *client.Client, id string, interval time.Duration) (*client.Batch, error) {
+	batch, err := c.GetBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	bar := newProgressBar(batch.EmailCount, "Processing")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for !batch.Done() {
+		_ = bar.Set(batch.ProcessedCount)
+
+		select {
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		case <-ticker.C:
+		}
+
+		if batch, err = c.GetBatch(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	_ = bar.Finish()
+
+	if batch.State != client.BatchCompleted {
+		return batch, fmt.Errorf("batch %s %s", batch.ID, batch.State)
+	}
+	return batch, nil
+}
+
+// downloadBatchCSV fetches a finished job's results and writes them next to
+// the rows of the original CSV, in the same layout as `validate --file`.
+// Rows the results leave out are written as errors, and make it return a
+// failedError once the output is complete.
+func downloadBatchCSV(ctx context.Context, c *client.Client, id, inputPath, column, outPath string) error {
+	results, err := c.DownloadBatch(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	byEmail := make(map[string]*client.ValidationResult, len(results))
+	for i := range results {
+		byEmail[strings.ToLower(strings.TrimSpace(results[i].Email))] = &results[i]
+	}
+
+	f, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("could not open file: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("could not read CSV header: %w", err)
+	}
+
+	emailColIdx, err := emailColumn(header, column)
+	if err != nil {
+		return err
+	}
+
+	outFile, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("could not create output file: %w", err)
+	}
+	// On success the file is closed below and its error reported; this
+	// only runs on early returns.
+	closed := false
+	defer func() {
+		if !closed {
+			outFile.Close()
+		}
+	}()
+
+	layout, err := newCSVLayout(header, defaultFields, defaultColumnPrefix)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(outFile)
+	if err := writer.Write(layout.header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	var counts tally
+	for {
+		row, readErr := reader.Read()
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("error reading CSV: %w", readErr)
+		}
+
+		email := ""
+		if emailColIdx < len(row) {
+			email = normalize.Address(row[emailColIdx], normalize.Options{})
+		}
+
+		var v *validation
+		switch result, found := byEmail[strings.ToLower(email)]; {
+		case email == "":
+		case !found:
+			v = &validation{err: fmt.Errorf("%w for %s", client.ErrMissingResult, email)}
+			counts.addFailure(v.err)
+		default:
+			counts.add(result.State)
+			v = &validation{result: result}
+		}
+		if err := writer.Write(layout.row(queued{row: row, email: email}, v)); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV output: %w", err)
+	}
+	closed = true
+	if err := outFile.Close(); err != nil {
+		return fmt.Errorf("failed to write CSV output: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "\nResults written to %s\n", outPath)
+	counts.print(os.Stderr)
+	return runOutcome(&counts, nil)
+}
+
+// emailColumn finds the email column or returns a usage error explaining
+// why it could not.
+func emailColumn(header []string, column string) (int, error) {
+	idx := findEmailColumn(header, column)
+	if idx != -1 {
+		return idx, nil
+	}
+	if column != "" {
+		return -1, usageErrorf("column %q not found in CSV header", column)
+	}
+	return -1, usageErrorf("could not detect email column — use --column to specify it")
+}
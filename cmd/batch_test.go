@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+)
+
+func TestDownloadBatchCSV(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/batches/b1/results" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, `{"emails":[
+			{"address":"jo@example.com","domain":"example.com","email_state":"ok","email_sub_state":"email_ok"},
+			{"address":"bad@example.com","domain":"example.com","email_state":"email_invalid","email_sub_state":"failed_smtp_check"}
+		]}`)
+	}))
+	defer srv.Close()
+	c := client.New("test-key").WithBaseURL(srv.URL).WithLimiter(client.NewTokenBucket(0, 1))
+
+	// The input was validated before, so its truelist_state column is
+	// overwritten in place rather than added again.
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.csv")
+	out := filepath.Join(dir, "out.csv")
+	input := "name,Email,truelist_state\n" +
+		"Jo,<Jo@Example.com>,old\n" +
+		"Bad,bad@example.com,old\n" +
+		"Nobody,,old\n" +
+		"Gone,gone@example.com,old\n"
+	if err := os.WriteFile(in, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The row missing from the results fails, but only after the output
+	// is complete.
+	err := downloadBatchCSV(context.Background(), c, "b1", in, "", out)
+	var failed *failedError
+	if !errors.As(err, &failed) || failed.count != 1 || !errors.Is(err, client.ErrMissingResult) {
+		t.Fatalf("downloadBatchCSV = %v, want 1 row missing its result", err)
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"name", "Email", "truelist_state", "truelist_normalized", "truelist_sub_state", "truelist_domain", "truelist_verified_at", "truelist_suggestion", "truelist_attempts", "truelist_source"},
+		{"Jo", "<Jo@Example.com>", "ok", "Jo@example.com", "email_ok", "example.com", "", "", "", ""},
+		{"Bad", "bad@example.com", "email_invalid", "bad@example.com", "failed_smtp_check", "example.com", "", "", "", ""},
+		{"Nobody", "", "", "", "", "", "", "", "", ""},
+		{"Gone", "gone@example.com", "error", "gone@example.com", "API returned no result for gone@example.com", "", "", "", "1", ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d:\n%q", len(got), len(want), got)
+	}
+	for i := range want {
+		if !slices.Equal(got[i], want[i]) {
+			t.Errorf("row %d:\n got %q\nwant %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCheckPollInterval(t *testing.T) {
+	defer func(d time.Duration) { flagBatchPollInterval = d }(flagBatchPollInterval)
+
+	for _, d := range []time.Duration{0, -time.Second} {
+		flagBatchPollInterval = d
+		if err := checkPollInterval(); exitCode(err) != exitUsage {
+			t.Errorf("--poll-interval %s: got %v, want a usage error", d, err)
+		}
+	}
+	flagBatchPollInterval = time.Millisecond
+	if err := checkPollInterval(); err != nil {
+		t.Errorf("--poll-interval 1ms: got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/cache"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/config"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cacheCmd.AddCommand(cacheStatsCmd, cachePruneCmd, cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local result cache",
+	Long: `Validation results are cached on disk so addresses checked recently are
+not paid for again. How long a result stays fresh depends on its state;
+change it with "truelist config set cache-ttl.<state> <duration>".
+
+Skip the cache for one run with "validate --no-cache", or re-validate and
+overwrite cached results with "validate --refresh".`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache size and contents",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := openCache()
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+		defer c.Close()
+
+		stats := c.Stats()
+		fresh := stats.Entries - stats.Expired
+
+		fmt.Printf("Path:     %s\n", stats.Path)
+		fmt.Printf("Size:     %d bytes\n", stats.Size)
+		fmt.Printf("Fresh:    %d\n", fresh)
+		fmt.Printf("Expired:  %d\n", stats.Expired)
+
+		states := make([]string, 0, len(stats.ByState))
+		for state := range stats.ByState {
+			states = append(states, state)
+		}
+		sort.Strings(states)
+		for _, state := range states {
+			fmt.Printf("  %-14s %d\n", state+":", stats.ByState[state])
+		}
+		return nil
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove expired entries and compact the cache file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := openCache()
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+		defer c.Close()
+
+		removed, err := c.Prune()
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		fmt.Printf("Removed %d expired entries\n", removed)
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all cached results",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := cache.DefaultPath()
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+		if err := cache.Clear(path); err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		fmt.Printf("Cleared %s\n", path)
+		return nil
+	},
+}
+
+// openCache opens the result cache with TTLs from the config file applied
+// over the defaults.
+func openCache() (*cache.Cache, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := make(cache.TTLs, len(cache.DefaultTTLs))
+	for state, d := range cache.DefaultTTLs {
+		ttl[state] = d
+	}
+	for state, s := range cfg.CacheTTL {
+		d, err := cache.ParseTTL(s)
+		if err != nil {
+			return nil, fmt.Errorf("config cache_ttl.%s: %w", state, err)
+		}
+		ttl[state] = d
+	}
+
+	path, err := cache.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return cache.Open(path, ttl)
+}
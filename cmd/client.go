@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/config"
+)
+
+var (
+	flagMaxAttempts  int
+	flagMaxRetryTime time.Duration
+	flagRate         float64
+	flagBurst        int
+)
+
+func init() {
+	rootCmd.PersistentFlags().IntVar(&flagMaxAttempts, "max-attempts", client.DefaultRetryPolicy.MaxAttempts, "Maximum attempts per request, including retries of transient failures")
+	rootCmd.PersistentFlags().DurationVar(&flagMaxRetryTime, "max-retry-time", client.DefaultRetryPolicy.MaxElapsed, "Maximum time to spend retrying a single request (0 for no limit)")
+	rootCmd.PersistentFlags().Float64Var(&flagRate, "rate", client.DefaultRateLimit, "Maximum API requests per second (overrides the rate-limit config key)")
+	rootCmd.PersistentFlags().IntVar(&flagBurst, "burst", client.DefaultBurst, "Requests that may be sent back to back before --rate applies (overrides the rate-burst config key)")
+}
+
+// newClient builds an API client from the config file and the global
+// client flags. Flags take precedence over config values.
+func newClient() (*client.Client, error) {
+	apiKey, err := config.GetAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if flagMaxAttempts < 1 {
+		return nil, usageErrorf("--max-attempts must be at least 1")
+	}
+
+	retry := client.DefaultRetryPolicy
+	retry.MaxAttempts = flagMaxAttempts
+	retry.MaxElapsed = flagMaxRetryTime
+
+	rate := flagRate
+	if !rootCmd.PersistentFlags().Changed("rate") && cfg.RateLimit != 0 {
+		rate = cfg.RateLimit
+	}
+	burst := flagBurst
+	if !rootCmd.PersistentFlags().Changed("burst") && cfg.RateBurst != 0 {
+		burst = cfg.RateBurst
+	}
+	if rate < 0 {
+		return nil, usageErrorf("--rate must not be negative")
+	}
+	if burst < 1 {
+		return nil, usageErrorf("--burst must be at least 1")
+	}
+
+	return client.New(apiKey).
+		WithRetryPolicy(retry).
+		WithLimiter(client.NewTokenBucket(rate, burst)), nil
+}
@@ -3,7 +3,10 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/cache"
 	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/config"
 	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/output"
 	"github.com/spf13/cobra"
@@ -24,35 +27,83 @@ var configSetCmd = &cobra.Command{
 	Short: "Set a configuration value",
 	Long: `Set a configuration value. Supported keys:
 
-  api-key    Your Truelist API key
+  api-key     Your Truelist API key
+  rate-limit  Maximum API requests per second (default 10)
+  rate-burst  Requests that may be sent back to back (default 1)
+  cache-ttl.<state>
+              How long cached results in a state stay fresh, e.g. 30d or 12h
+              (states: ok, email_invalid, accept_all, unknown)
 
 Example:
-  truelist config set api-key tk_live_abc123`,
+  truelist config set api-key tk_live_abc123
+  truelist config set rate-limit 25
+  truelist config set cache-ttl.accept_all 3d`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		key, value := args[0], args[1]
 
-		switch key {
-		case "api-key":
-			cfg, err := config.Load()
-			if err != nil {
-				output.PrintError(os.Stderr, err.Error())
+		cfg, err := config.LoadFile()
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		switch {
+		case strings.HasPrefix(key, "cache-ttl."):
+			state := strings.TrimPrefix(key, "cache-ttl.")
+			if _, known := cache.DefaultTTLs[state]; !known {
+				err := usageErrorf("unknown state in %s (supported: ok, email_invalid, accept_all, unknown)", key)
+				output.PrintError(os.Stderr, err)
+				return err
+			}
+			if _, parseErr := cache.ParseTTL(value); parseErr != nil {
+				err := usageErrorf("%s: %s", key, parseErr)
+				output.PrintError(os.Stderr, err)
 				return err
 			}
+			if cfg.CacheTTL == nil {
+				cfg.CacheTTL = make(map[string]string)
+			}
+			cfg.CacheTTL[state] = value
+
+		case key == "api-key":
 			cfg.APIKey = value
-			if err := config.Save(cfg); err != nil {
-				output.PrintError(os.Stderr, err.Error())
+
+		case key == "rate-limit":
+			rate, parseErr := strconv.ParseFloat(value, 64)
+			if parseErr != nil || rate < 0 {
+				err := usageErrorf("rate-limit must be a non-negative number, got %q", value)
+				output.PrintError(os.Stderr, err)
 				return err
 			}
+			cfg.RateLimit = rate
 
-			fp, _ := config.FilePath()
-			fmt.Printf("API key saved to %s\n", fp)
-			return nil
+		case key == "rate-burst":
+			burst, parseErr := strconv.Atoi(value)
+			if parseErr != nil || burst < 1 {
+				err := usageErrorf("rate-burst must be a positive integer, got %q", value)
+				output.PrintError(os.Stderr, err)
+				return err
+			}
+			cfg.RateBurst = burst
 
 		default:
-			err := fmt.Errorf("unknown config key: %s (supported: api-key)", key)
-			output.PrintError(os.Stderr, err.Error())
+			err := usageErrorf("unknown config key: %s (supported: api-key, rate-limit, rate-burst, cache-ttl.<state>)", key)
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		if err := config.Save(cfg); err != nil {
+			output.PrintError(os.Stderr, err)
 			return err
 		}
+
+		fp, _ := config.FilePath()
+		if key == "api-key" {
+			fmt.Printf("API key saved to %s\n", fp)
+		} else {
+			fmt.Printf("%s saved to %s\n", key, fp)
+		}
+		return nil
 	},
 }
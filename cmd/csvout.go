@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+)
+
+// csvFields are the fields CSV output can have: the result fields;
+// "normalized", the address as it was validated after normalization; and
+// "original" and "correction", which record what --fix-typos found. The
+// input's own email column is left as it was.
+var csvFields = append(slices.Clip(client.ResultFields), "normalized", "original", "correction")
+
+// defaultFields are the fields added to CSV output unless --fields says
+// otherwise.
+var defaultFields = []string{"normalized", "state", "sub_state", "domain", "verified_at", "suggestion", "attempts", "source"}
+
+// defaultColumnPrefix is prepended to result field names to name their
+// CSV columns.
+const defaultColumnPrefix = "truelist_"
+
+// csvLayout places result fields in the columns of an output CSV.
+type csvLayout struct {
+	input  []string // the input's header
+	header []string // the output's header
+	fields []string
+	cols   []int // output column of each field
+}
+
+// newCSVLayout lays out an output CSV for an input header. Result columns
+// the input already has, such as truelist_state in a file validated
+// before, are overwritten in place; the rest are appended. Only columns
+// named with the prefix are overwritten: without one, a field named like
+// an input column, such as state, is a usage error rather than a reason
+// to destroy the user's data.
+func newCSVLayout(input, fields []string, prefix string) (*csvLayout, error) {
+	l := &csvLayout{
+		input:  input,
+		header: slices.Clip(slices.Clone(input)),
+		fields: fields,
+	}
+	for _, field := range fields {
+		name := prefix + field
+		col := slices.IndexFunc(input, func(h string) bool {
+			return strings.EqualFold(strings.TrimSpace(h), name)
+		})
+		switch {
+		case col >= 0 && prefix == "":
+			return nil, usageErrorf("field %q has the same name as the input column %q — set --column-prefix or leave the field out of --fields", field, input[col])
+		case col < 0:
+			col = len(l.header)
+			l.header = append(l.header, name)
+		}
+		l.cols = append(l.cols, col)
+	}
+	return l, nil
+}
+
+// stateColumn returns the output column of the state field, or -1 if it
+// is not included.
+func (l *csvLayout) stateColumn() int {
+	if i := slices.Index(l.fields, "state"); i >= 0 {
+		return l.cols[i]
+	}
+	return -1
+}
+
+// row returns the output row for an input row and its validation. A row
+// without an email (v is nil) gets empty result columns. A row that failed
+// to validate gets the state "error", with the error message as its
+// sub-state.
+func (l *csvLayout) row(q queued, v *validation) []string {
+	out := make([]string, len(l.header))
+	copy(out, q.row)
+	for i, field := range l.fields {
+		value := ""
+		switch {
+		case field == "normalized":
+			value = q.email
+		case field == "original":
+			value = q.original
+		case field == "correction":
+			value = q.correction
+		case v == nil:
+		case v.err != nil:
+			value = errorField(field, q.email, v.err)
+		default:
+			value, _ = v.result.Field(field)
+		}
+		out[l.cols[i]] = value
+	}
+	return out
+}
+
+// withField adds field to a list of CSV fields unless it is there already,
+// placing it after "normalized" if that is included, or first otherwise.
+func withField(fields []string, field string) []string {
+	if slices.Contains(fields, field) {
+		return fields
+	}
+	i := slices.Index(fields, "normalized") + 1
+	return slices.Insert(slices.Clip(fields), i, field)
+}
+
+// errorField returns the value of a result field for a failed validation.
+func errorField(field, email string, err error) string {
+	switch field {
+	case "email":
+		return email
+	case "state":
+		return "error"
+	case "sub_state":
+		return err.Error()
+	case "attempts":
+		return strconv.Itoa(client.Attempts(err))
+	default:
+		return ""
+	}
+}
+
+// defaultOutputPath returns <input>_validated<ext> for an input CSV path.
+func defaultOutputPath(input string) string {
+	ext := filepath.Ext(input)
+	base := strings.TrimSuffix(input, ext)
+	return base + "_validated" + ext
+}
+
+// Output formats for file mode.
+const (
+	formatCSV   = "csv"
+	formatJSON  = "json"
+	formatJSONL = "jsonl"
+	formatText  = "text" // --format
+)
+
+// formatExt returns the file extension for a format.
+func formatExt(format string) string {
+	if format == formatText {
+		return ".txt"
+	}
+	return "." + format
+}
+
+// rowOutput is where file mode writes its rows.
+type rowOutput interface {
+	// write writes one row with its validation and flushes it, so a crash
+	// or kill loses nothing already paid for. v is nil for a row without
+	// an email.
+	write(q queued, v *validation) error
+	// paths lists the files written, for the summary.
+	paths() []string
+	close() error
+}
+
+// openRowFile opens a single output file in the given format; tmpl is
+// the --format template for formatText. When resuming, it reopens the file
+// an interrupted run left and returns the number of rows it already holds.
+// The path "-" means stdout.
+func openRowFile(path string, layout *csvLayout, tmpl *template.Template, format string, resume bool, counts *tally) (rowOutput, int, error) {
+	switch format {
+	case formatText:
+		t, err := createText(path, tmpl)
+		if err != nil {
+			return nil, 0, err
+		}
+		return t, 0, nil
+	case formatJSON, formatJSONL:
+		if resume {
+			j, rows, err := resumeJSONL(path, layout.input, counts)
+			if err != nil {
+				return nil, 0, err
+			}
+			return j, rows, nil
+		}
+		j, err := createJSON(path, layout.input, format == formatJSON)
+		if err != nil {
+			return nil, 0, err
+		}
+		return j, 0, nil
+	default:
+		if resume {
+			c, rows, err := resumeCSV(path, layout, counts)
+			if err != nil {
+				return nil, 0, err
+			}
+			return c, rows, nil
+		}
+		c, err := createCSV(path, layout)
+		if err != nil {
+			return nil, 0, err
+		}
+		return c, 0, nil
+	}
+}
+
+// createOutputFile creates an output file, or returns stdout for "-".
+func createOutputFile(path string) (*os.File, error) {
+	if path == "-" {
+		return os.Stdout, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create output file: %w", err)
+	}
+	return f, nil
+}
+
+// closeOutputFile closes an output file unless it is stdout.
+func closeOutputFile(f *os.File) error {
+	if f == os.Stdout {
+		return nil
+	}
+	return f.Close()
+}
+
+// csvFile is an output CSV that is flushed after every row.
+type csvFile struct {
+	path   string
+	f      *os.File
+	w      *csv.Writer
+	layout *csvLayout
+}
+
+// createCSV creates an output CSV and writes its header.
+func createCSV(path string, layout *csvLayout) (*csvFile, error) {
+	f, err := createOutputFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &csvFile{path: path, f: f, w: csv.NewWriter(f), layout: layout}
+	if err := c.flush(layout.header); err != nil {
+		closeOutputFile(f)
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+	return c, nil
+}
+
+// resumeCSV reopens an output CSV left by an interrupted run. See
+// resumeOutput.
+func resumeCSV(path string, layout *csvLayout, counts *tally) (*csvFile, int, error) {
+	f, rows, err := resumeOutput(path, layout, counts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &csvFile{path: path, f: f, w: csv.NewWriter(f), layout: layout}, rows, nil
+}
+
+func (c *csvFile) write(q queued, v *validation) error {
+	if err := c.flush(c.layout.row(q, v)); err != nil {
+		return fmt.Errorf("failed to write row: %w", err)
+	}
+	return nil
+}
+
+// flush writes a record and flushes it to the file.
+func (c *csvFile) flush(record []string) error {
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvFile) paths() []string {
+	return []string{c.path}
+}
+
+func (c *csvFile) close() error {
+	return closeOutputFile(c.f)
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/normalize"
+)
+
+// deduper makes a file run validate each unique address once. The
+// producer asks see whether a row's address is a repeat; the consumer,
+// which handles rows in order and so always reaches an address's first row
+// before its repeats, records each first result for the repeats to reuse.
+// A nil deduper treats every row as new.
+//
+// Only the addresses are kept in memory. Results go to a temporary file,
+// so a run over millions of unique addresses doesn't hold all of their
+// results.
+type deduper struct {
+	drop bool // repeats are left out of the output
+
+	mu    sync.Mutex
+	seen  map[string]int64 // offset of the result in store, or seenWritten or seenPending
+	store *os.File         // created by the first record
+	w     *bufio.Writer
+	size  int64 // bytes written to store, including those still in w
+}
+
+// Values of deduper.seen other than offsets into the store.
+const (
+	seenWritten = -1 // on a row written by an interrupted run
+	seenPending = -2 // being validated, with no result stored yet
+)
+
+// storedValidation is a validation as kept in the store. An error keeps
+// only its message: repeats show it, but the first row's error is the one
+// that decides the exit code.
+type storedValidation struct {
+	Result *client.ValidationResult `json:"result,omitempty"`
+	Err    string                   `json:"err,omitempty"`
+}
+
+func newDeduper(drop bool) *deduper {
+	return &deduper{
+		drop: drop,
+		seen: make(map[string]int64),
+	}
+}
+
+// see records a row's address and reports whether it repeats an earlier
+// row, in which case it should not be validated again, and whether it is
+// the first row with the address, counting rows written by an interrupted
+// run.
+func (d *deduper) see(email string) (key string, repeat, first bool) {
+	key = normalize.Key(email)
+	if d == nil {
+		return key, false, true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	off, ok := d.seen[key]
+	switch {
+	case !ok:
+	case off != seenWritten:
+		return key, true, false
+	case d.drop:
+		// First seen on a row written by the interrupted run; this row
+		// only needs dropping, not a result.
+		return key, true, false
+	}
+	d.seen[key] = seenPending
+	return key, false, !ok
+}
+
+// skip records the address of a row written by an interrupted run and
+// reports whether it repeats an earlier row. With --dedupe-output, such a
+// row was left out of the output.
+func (d *deduper) skip(email string) bool {
+	if d == nil || email == "" {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := normalize.Key(email)
+	if _, ok := d.seen[key]; ok {
+		return true
+	}
+	d.seen[key] = seenWritten
+	return false
+}
+
+// record stores the validation of an address's first row.
+func (d *deduper) record(key string, v *validation) error {
+	if d == nil {
+		return nil
+	}
+	sv := storedValidation{Result: v.result}
+	if v.err != nil {
+		sv.Result, sv.Err = nil, v.err.Error()
+	}
+	data, err := json.Marshal(sv)
+	if err != nil {
+		return fmt.Errorf("could not store result for repeated rows: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.store == nil {
+		if d.store, err = os.CreateTemp("", "truelist-dedupe-*.jsonl"); err != nil {
+			return fmt.Errorf("could not store results for repeated rows: %w", err)
+		}
+		d.w = bufio.NewWriter(d.store)
+	}
+	if _, err := d.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("could not store result for repeated rows: %w", err)
+	}
+	d.seen[key] = d.size
+	d.size += int64(len(data)) + 1
+	return nil
+}
+
+// result returns the validation recorded for key, or nil if there is
+// none. It is not marked cached or local: the repeat reused a result from
+// this run, not the cache or a precheck.
+func (d *deduper) result(key string) (*validation, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	off, ok := d.seen[key]
+	if !ok || off < 0 {
+		return nil, nil
+	}
+	if err := d.w.Flush(); err != nil {
+		return nil, fmt.Errorf("could not store results for repeated rows: %w", err)
+	}
+	line, err := bufio.NewReader(io.NewSectionReader(d.store, off, d.size-off)).ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("could not read result for repeated rows: %w", err)
+	}
+	var sv storedValidation
+	if err := json.Unmarshal(line, &sv); err != nil {
+		return nil, fmt.Errorf("could not read result for repeated rows: %w", err)
+	}
+	if sv.Err != "" {
+		return &validation{err: errors.New(sv.Err)}, nil
+	}
+	return &validation{result: sv.Result}, nil
+}
+
+// close deletes the stored results.
+func (d *deduper) close() {
+	if d == nil || d.store == nil {
+		return
+	}
+	d.store.Close()
+	os.Remove(d.store.Name())
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+)
+
+func TestDeduper(t *testing.T) {
+	d := newDeduper(false)
+
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		if _, repeat, first := d.see(email); repeat || !first {
+			t.Fatalf("see(%s) = repeat %v, first %v, want a new address", email, repeat, first)
+		}
+	}
+	keyA, repeat, first := d.see("a@EXAMPLE.com")
+	if !repeat || first {
+		t.Fatalf("see(a@EXAMPLE.com) = repeat %v, first %v, want a repeat", repeat, first)
+	}
+	keyB, _, _ := d.see("b@example.com")
+
+	if err := d.record(keyA, &validation{result: &client.ValidationResult{Email: "a@example.com", State: "ok"}, cached: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.record(keyB, &validation{err: errors.New("API error (status 500)")}); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := d.result(keyA)
+	if err != nil || v == nil || v.result.State != "ok" || v.cached || v.local {
+		t.Errorf("result(a) = %+v, %v, want an uncached ok result", v, err)
+	}
+	v, err = d.result(keyB)
+	if err != nil || v == nil || v.err == nil || v.err.Error() != "API error (status 500)" {
+		t.Errorf("result(b) = %+v, %v, want the first row's error", v, err)
+	}
+	if v, err := d.result("c@example.com"); v != nil || err != nil {
+		t.Errorf("result(c) = %+v, %v, want nothing", v, err)
+	}
+
+	path := d.store.Name()
+	d.close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("stored results not removed: %v", err)
+	}
+}
+
+func TestDeduperResume(t *testing.T) {
+	// Rows written by an interrupted run: the first a@ row, and its
+	// repeat.
+	keep := newDeduper(false)
+	drop := newDeduper(true)
+	for _, d := range []*deduper{keep, drop} {
+		if d.skip("a@example.com") {
+			t.Error("skip: first row seen as a repeat")
+		}
+		if !d.skip("a@example.com") {
+			t.Error("skip: repeated row not seen as a repeat")
+		}
+	}
+
+	// A later repeat still needs a result unless repeats are dropped.
+	// Either way, it is not a new address.
+	if _, repeat, first := keep.see("a@example.com"); repeat || first {
+		t.Errorf("without --dedupe-output, see = repeat %v, first %v, want a@ validated again as a known address", repeat, first)
+	}
+	if _, repeat, first := drop.see("a@example.com"); !repeat || first {
+		t.Errorf("with --dedupe-output, see = repeat %v, first %v, want a@ dropped", repeat, first)
+	}
+}
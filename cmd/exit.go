@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/config"
+)
+
+// Process exit codes. These are part of the CLI's public interface; keep
+// them stable and documented in the README.
+const (
+	exitOK          = 0
+	exitError       = 1 // unclassified failure
+	exitUsage       = 2 // invalid flags or arguments
+	exitAuth        = 3 // missing or rejected API key
+	exitQuota       = 4 // out of credits
+	exitRateLimited = 5 // still rate limited after retrying
+	exitAPI         = 6 // any other API error response
+	exitNetwork     = 7 // the API could not be reached
+
+	// Validation outcomes, used only for states listed in --fail-on.
+	exitInvalid   = 10 // a result was email_invalid
+	exitAcceptAll = 11 // a result was accept_all
+	exitUnknown   = 12 // a result was unknown
+
+	exitInterrupted = 130 // stopped by SIGINT or SIGTERM, as shells report it
+)
+
+// errInterrupted is the cancellation cause of the root context when the
+// process receives SIGINT or SIGTERM.
+var errInterrupted = errors.New("interrupted")
+
+// usageError marks an error caused by invalid flags or arguments.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+func usageErrorf(format string, a ...any) error {
+	return usageError{err: fmt.Errorf(format, a...)}
+}
+
+// failOnCodes are the states --fail-on accepts and their exit codes. When
+// results are in several failing states, the first one listed here wins.
+var failOnCodes = []failOnCode{
+	{"email_invalid", exitInvalid},
+	{"accept_all", exitAcceptAll},
+	{"unknown", exitUnknown},
+}
+
+type failOnCode struct {
+	state string
+	code  int
+}
+
+// outcomeError reports that validation worked, but some results were in a
+// state listed in --fail-on.
+type outcomeError struct {
+	state string
+	count int
+	code  int
+}
+
+func (e *outcomeError) Error() string {
+	if e.count == 1 {
+		return fmt.Sprintf("1 result was %s", e.state)
+	}
+	return fmt.Sprintf("%d results were %s", e.count, e.state)
+}
+
+// failedError reports that a bulk run completed, but some rows could not
+// be validated. It wraps the first failure, so the exit code says what
+// went wrong.
+type failedError struct {
+	count int
+	first error
+}
+
+func (e *failedError) Error() string {
+	if e.count == 1 {
+		return fmt.Sprintf("1 row failed to validate: %s", e.first)
+	}
+	return fmt.Sprintf("%d rows failed to validate; the first: %s", e.count, e.first)
+}
+
+func (e *failedError) Unwrap() error { return e.first }
+
+// checkFailOn normalizes --fail-on in place, rejecting unknown states.
+func checkFailOn(states []string) error {
+	for i, s := range states {
+		s = strings.ToLower(strings.TrimSpace(s))
+		known := slices.ContainsFunc(failOnCodes, func(fc failOnCode) bool { return fc.state == s })
+		if !known {
+			return usageErrorf("--fail-on accepts email_invalid, accept_all and unknown, not %q", states[i])
+		}
+		states[i] = s
+	}
+	return nil
+}
+
+// failOnOutcome returns an outcomeError if counts include a state listed in
+// failOn, and nil otherwise.
+func failOnOutcome(counts *tally, failOn []string) error {
+	for _, fc := range failOnCodes {
+		if !slices.Contains(failOn, fc.state) {
+			continue
+		}
+		if n := counts.count(fc.state); n > 0 {
+			return &outcomeError{state: fc.state, count: n, code: fc.code}
+		}
+	}
+	return nil
+}
+
+// runOutcome returns the error a completed bulk run exits with: a
+// failedError if any row failed to validate, since its results are then
+// incomplete, and otherwise the result of failOnOutcome.
+func runOutcome(counts *tally, failOn []string) error {
+	if counts.failed > 0 {
+		return &failedError{count: counts.failed, first: counts.firstFailure}
+	}
+	return failOnOutcome(counts, failOn)
+}
+
+// exitCode maps an error returned by a command to a process exit code.
+func exitCode(err error) int {
+	var ue usageError
+	var oe *outcomeError
+	var apiErr *client.APIError
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &oe):
+		return oe.code
+	case errors.Is(err, errInterrupted), errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.As(err, &ue):
+		return exitUsage
+	case errors.Is(err, client.ErrUnauthorized), errors.Is(err, config.ErrNoAPIKey):
+		return exitAuth
+	case errors.Is(err, client.ErrQuotaExceeded):
+		return exitQuota
+	case errors.Is(err, client.ErrRateLimited):
+		return exitRateLimited
+	case errors.As(err, &apiErr):
+		return exitAPI
+	case errors.Is(err, client.ErrTransport):
+		return exitNetwork
+	default:
+		return exitError
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/config"
+)
+
+func TestExitCode(t *testing.T) {
+	apiErr := func(status int, code string) error {
+		return &client.APIError{StatusCode: status, Code: code}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, exitOK},
+		{"plain", errors.New("boom"), exitError},
+		{"usage", usageErrorf("bad flag"), exitUsage},
+		{"wrapped usage", fmt.Errorf("validate: %w", usageErrorf("bad flag")), exitUsage},
+
+		{"401", apiErr(401, ""), exitAuth},
+		{"403", apiErr(403, ""), exitAuth},
+		{"no API key", config.ErrNoAPIKey, exitAuth},
+		{"402", apiErr(402, ""), exitQuota},
+		{"quota code", apiErr(400, "quota_exceeded"), exitQuota},
+		{"credits code", apiErr(422, "insufficient_credits"), exitQuota},
+		{"429", apiErr(429, ""), exitRateLimited},
+		{"429 after retries", &client.RetryError{Attempts: 5, Err: apiErr(429, "")}, exitRateLimited},
+		{"500", apiErr(500, ""), exitAPI},
+		{"422", apiErr(422, "invalid_email"), exitAPI},
+		{"404", apiErr(404, ""), exitAPI},
+		{"transport", fmt.Errorf("%w: %w", client.ErrTransport, errors.New("connection refused")), exitNetwork},
+		{"transport after retries", &client.RetryError{Attempts: 3, Err: fmt.Errorf("%w: timeout", client.ErrTransport)}, exitNetwork},
+
+		{"interrupted", errInterrupted, exitInterrupted},
+		{"cancelled", fmt.Errorf("request: %w", context.Canceled), exitInterrupted},
+
+		{"invalid outcome", &outcomeError{state: "email_invalid", count: 2, code: exitInvalid}, exitInvalid},
+		{"accept_all outcome", &outcomeError{state: "accept_all", count: 1, code: exitAcceptAll}, exitAcceptAll},
+		{"unknown outcome", &outcomeError{state: "unknown", count: 1, code: exitUnknown}, exitUnknown},
+
+		{"failed rows, API", &failedError{count: 2, first: apiErr(500, "")}, exitAPI},
+		{"failed rows, quota", &failedError{count: 1, first: apiErr(402, "")}, exitQuota},
+		{"failed rows, transport", &failedError{count: 3, first: client.ErrTransport}, exitNetwork},
+		{"failed rows, missing result", &failedError{count: 1, first: client.ErrMissingResult}, exitError},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("%s: exitCode(%v) = %d, want %d", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCheckFailOn(t *testing.T) {
+	states := []string{" Email_Invalid", "UNKNOWN"}
+	if err := checkFailOn(states); err != nil {
+		t.Fatalf("checkFailOn = %v", err)
+	}
+	if want := []string{"email_invalid", "unknown"}; !slices.Equal(states, want) {
+		t.Errorf("states = %q, want %q", states, want)
+	}
+
+	err := checkFailOn([]string{"ok"})
+	if err == nil || exitCode(err) != exitUsage {
+		t.Errorf("checkFailOn(ok) = %v, want a usage error", err)
+	}
+}
+
+func TestRunOutcome(t *testing.T) {
+	var counts tally
+	counts.add("ok")
+	counts.add("unknown")
+	counts.add("email_invalid")
+
+	if err := runOutcome(&counts, nil); err != nil {
+		t.Errorf("runOutcome without --fail-on = %v", err)
+	}
+	if got := exitCode(runOutcome(&counts, []string{"unknown", "email_invalid"})); got != exitInvalid {
+		t.Errorf("exit code with two failing states = %d, want email_invalid's %d", got, exitInvalid)
+	}
+	if got := exitCode(runOutcome(&counts, []string{"accept_all"})); got != exitOK {
+		t.Errorf("exit code with no accept_all results = %d, want %d", got, exitOK)
+	}
+
+	// Failed rows outrank --fail-on: the results are incomplete.
+	counts.addFailure(&client.APIError{StatusCode: 503})
+	counts.addFailure(client.ErrTransport)
+	err := runOutcome(&counts, []string{"email_invalid"})
+	var fe *failedError
+	if !errors.As(err, &fe) || fe.count != 2 {
+		t.Fatalf("runOutcome = %v, want 2 failed rows", err)
+	}
+	if got := exitCode(err); got != exitAPI {
+		t.Errorf("exit code = %d, want the first failure's %d", got, exitAPI)
+	}
+}
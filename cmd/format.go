@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/output"
+)
+
+// parseFormat parses a --format template, returning nil when it is not
+// set. The template is tried on sample, the zero value of what it will
+// be given, so a misspelled field is a usage error rather than a failure
+// halfway through a run.
+func parseFormat(text string, sample any) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	t, err := output.ParseTemplate(text)
+	if err == nil {
+		err = t.Execute(io.Discard, sample)
+	}
+	if err != nil {
+		return nil, usageErrorf("invalid --format template: %s", err)
+	}
+	return t, nil
+}
+
+// textOutput writes file mode's rows through a --format template, one
+// execution per row. Rows without an email are left out, and a row that
+// failed to validate is given to the template as a result with the state
+// "error" and the error message as its sub-state, as in CSV output.
+type textOutput struct {
+	path string
+	f    *os.File
+	tmpl *template.Template
+}
+
+// createText creates a --format output file.
+func createText(path string, tmpl *template.Template) (*textOutput, error) {
+	f, err := createOutputFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &textOutput{path: path, f: f, tmpl: tmpl}, nil
+}
+
+func (t *textOutput) write(q queued, v *validation) error {
+	var r *client.ValidationResult
+	switch {
+	case v == nil:
+		return nil
+	case v.err != nil:
+		r = &client.ValidationResult{Email: q.email, State: "error", SubState: v.err.Error()}
+	default:
+		r = v.result
+	}
+	return output.PrintTemplate(t.f, t.tmpl, r)
+}
+
+func (t *textOutput) paths() []string {
+	return []string{t.path}
+}
+
+func (t *textOutput) close() error {
+	return closeOutputFile(t.f)
+}
+
+// checkFields validates a list of fields given to flag, such as --fields,
+// against the known ones, returning defaults if it is empty.
+func checkFields(flag string, fields, known, defaults []string) ([]string, error) {
+	if len(fields) == 0 {
+		return defaults, nil
+	}
+	for i, f := range fields {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if !slices.Contains(known, f) {
+			return nil, usageErrorf("unknown field %q in %s (fields: %s)", fields[i], flag, strings.Join(known, ", "))
+		}
+		if slices.Contains(fields[:i], f) {
+			return nil, usageErrorf("field %q is listed twice in %s", f, flag)
+		}
+		fields[i] = f
+	}
+	return fields, nil
+}
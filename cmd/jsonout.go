@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/output"
+)
+
+// jsonOutput writes file mode's rows as output.RowRecord values: one per
+// line for JSONL, or as the elements of a single array for JSON.
+type jsonOutput struct {
+	path   string
+	f      *os.File
+	header []string
+	array  bool
+	n      int // records written
+}
+
+// createJSON creates a JSON or JSONL output file. header is the input's
+// header, used to key each row.
+func createJSON(path string, header []string, array bool) (*jsonOutput, error) {
+	f, err := createOutputFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if array {
+		if _, err := f.WriteString("["); err != nil {
+			closeOutputFile(f)
+			return nil, fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return &jsonOutput{path: path, f: f, header: header, array: array}, nil
+}
+
+// resumeJSONL reopens a JSONL output file left by an interrupted run. It
+// counts the complete records already written, tallying their states into
+// counts, and truncates a partially written final line.
+func resumeJSONL(path string, header []string, counts *tally) (*jsonOutput, int, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not open output file to resume: %w", err)
+	}
+
+	r := bufio.NewReader(f)
+	rows := 0
+	var end int64
+	for {
+		line, readErr := r.ReadBytes('\n')
+		if errors.Is(readErr, io.EOF) {
+			// Anything after the last newline was cut off mid-write; it
+			// will be written again.
+			break
+		}
+		if readErr != nil {
+			f.Close()
+			return nil, 0, fmt.Errorf("could not read output file: %w", readErr)
+		}
+
+		var rec struct {
+			Result *struct {
+				State string `json:"email_state"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(line, &rec); err != nil {
+			f.Close()
+			return nil, 0, fmt.Errorf("%s is not JSON Lines output from this command — start over without --resume", path)
+		}
+		if rec.Result != nil {
+			counts.add(rec.Result.State)
+		}
+		rows++
+		end += int64(len(line))
+	}
+
+	if err := f.Truncate(end); err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("could not truncate output file: %w", err)
+	}
+	if _, err := f.Seek(end, io.SeekStart); err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("could not seek output file: %w", err)
+	}
+	return &jsonOutput{path: path, f: f, header: header}, rows, nil
+}
+
+func (j *jsonOutput) write(q queued, v *validation) error {
+	rec := output.RowRecord{
+		Row:        output.Row{Header: j.header, Values: q.row},
+		Original:   q.original,
+		Correction: q.correction,
+	}
+	switch {
+	case v == nil:
+	case v.err != nil:
+		e := output.NewErrorRecord(q.email, v.err)
+		rec.Error = &e
+	default:
+		rec.Result = v.result
+	}
+
+	var data []byte
+	var err error
+	if j.array {
+		data, err = json.MarshalIndent(rec, "  ", "  ")
+		sep := ",\n  "
+		if j.n == 0 {
+			sep = "\n  "
+		}
+		data = append([]byte(sep), data...)
+	} else {
+		data, err = json.Marshal(rec)
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode row: %w", err)
+	}
+
+	// One write per record, so each reaches the file whole.
+	if _, err := j.f.Write(data); err != nil {
+		return fmt.Errorf("failed to write row: %w", err)
+	}
+	j.n++
+	return nil
+}
+
+func (j *jsonOutput) paths() []string {
+	return []string{j.path}
+}
+
+// close ends the array for JSON output, so even an interrupted run leaves
+// a valid document, and closes the file. Closing again does nothing.
+func (j *jsonOutput) close() error {
+	if j.f == nil {
+		return nil
+	}
+	f := j.f
+	j.f = nil
+
+	if j.array {
+		end := "\n]\n"
+		if j.n == 0 {
+			end = "]\n"
+		}
+		if _, err := f.WriteString(end); err != nil {
+			closeOutputFile(f)
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return closeOutputFile(f)
+}
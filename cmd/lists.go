@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/lists"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// maxListSize bounds how much `lists update` reads from a file or URL.
+const maxListSize = 64 << 20
+
+// listFetchTimeout bounds how long `lists update` waits for a URL.
+const listFetchTimeout = time.Minute
+
+var flagListFrom string
+
+func init() {
+	listsUpdateCmd.Flags().StringVar(&flagListFrom, "from", "", "File or http(s) URL to read the new list from")
+	listsCmd.AddCommand(listsStatsCmd, listsUpdateCmd, listsResetCmd)
+	rootCmd.AddCommand(listsCmd)
+}
+
+var listsCmd = &cobra.Command{
+	Use:   "lists",
+	Short: "Manage the lists used by local checks",
+	Long: `"validate --precheck local" settles addresses on disposable domains and
+role accounts such as info@ locally, without spending a credit. It uses two
+lists built into the CLI: "disposable" (domains) and "roles" (local parts).
+A third, "providers", holds the popular provider domains that "truelist
+suggest" and "validate --fix-typos" correct misspellings of.
+
+Replace a list with a newer one with "truelist lists update <list> --from
+<file|url>", and go back to the built-in list with "truelist lists reset".`,
+}
+
+var listsStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show where each list comes from and its size",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, name := range lists.Names {
+			l, err := lists.Load(name)
+			if err != nil {
+				output.PrintError(os.Stderr, err)
+				return err
+			}
+			source := "built in"
+			if l.Path != "" {
+				source = fmt.Sprintf("%s (updated %s)", l.Path, l.UpdatedAt.Format(time.DateTime))
+			}
+			fmt.Printf("%-11s %6d entries  %s\n", name+":", l.Len(), source)
+		}
+		return nil
+	},
+}
+
+var listsUpdateCmd = &cobra.Command{
+	Use:   "update [disposable|roles|providers] --from <file|url>",
+	Short: "Replace a list with a newer one",
+	Long: `Replace a list with one read from a file or downloaded from an http(s)
+URL. The list defaults to "disposable". It must be plain text with one
+entry per line; blank lines and lines starting with # are ignored.
+
+Example:
+  truelist lists update --from https://example.com/disposable_domains.txt
+  truelist lists update roles --from roles.txt`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := lists.Disposable
+		if len(args) == 1 {
+			name = args[0]
+		}
+		var err error
+		switch {
+		case !slices.Contains(lists.Names, name):
+			err = usageErrorf("unknown list %q (lists: %s)", name, strings.Join(lists.Names, ", "))
+		case flagListFrom == "":
+			err = usageErrorf("--from is required")
+		}
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		data, err := readListSource(cmd.Context(), flagListFrom)
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+		n, err := lists.Update(name, data)
+		if err != nil {
+			err = fmt.Errorf("could not update %s list from %s: %w", name, flagListFrom, err)
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		fmt.Printf("Updated %s list: %d entries\n", name, n)
+		return nil
+	},
+}
+
+var listsResetCmd = &cobra.Command{
+	Use:   "reset [disposable|roles|providers]",
+	Short: "Go back to the built-in lists",
+	Long:  "Delete updated copies of the lists, so the ones built into the CLI are used again. Resets every list unless one is named.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names := lists.Names
+		if len(args) == 1 {
+			if !slices.Contains(lists.Names, args[0]) {
+				err := usageErrorf("unknown list %q (lists: %s)", args[0], strings.Join(lists.Names, ", "))
+				output.PrintError(os.Stderr, err)
+				return err
+			}
+			names = args
+		}
+		for _, name := range names {
+			if err := lists.Reset(name); err != nil {
+				output.PrintError(os.Stderr, err)
+				return err
+			}
+			fmt.Printf("Reset %s list to the built-in one\n", name)
+		}
+		return nil
+	},
+}
+
+// readListSource reads a new list from a file or an http(s) URL.
+func readListSource(ctx context.Context, from string) ([]byte, error) {
+	if !strings.HasPrefix(from, "http://") && !strings.HasPrefix(from, "https://") {
+		f, err := os.Open(from)
+		if err != nil {
+			return nil, fmt.Errorf("could not open list: %w", err)
+		}
+		defer f.Close()
+		return readList(f, from)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, listFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, from, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid list URL: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not download list: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not download list: %s returned %s", from, resp.Status)
+	}
+	return readList(resp.Body, from)
+}
+
+func readList(r io.Reader, from string) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxListSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("could not read list: %w", err)
+	}
+	if len(data) > maxListSize {
+		return nil, fmt.Errorf("%s is larger than %d MB", from, maxListSize>>20)
+	}
+	return data, nil
+}
@@ -0,0 +1,436 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/cache"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/normalize"
+)
+
+// validation is the outcome of validating a single email.
+type validation struct {
+	result *client.ValidationResult
+	err    error
+	cached bool // result was served from the local cache
+	local  bool // result was decided by a local precheck
+}
+
+// batchLinger is how long the pool waits for a batch to fill before
+// sending a partial one, so slow producers such as `tail -f` still see
+// results promptly.
+const batchLinger = 250 * time.Millisecond
+
+// interruptGrace is how long requests already sent may keep running after
+// an interrupt, so results that are paid for still get written.
+const interruptGrace = 10 * time.Second
+
+// validatorPool fans validations out to a fixed number of workers. All
+// workers share the same client, and therefore its rate limiter. Submitted
+// emails are grouped into ValidateBatch requests of up to batchSize.
+type validatorPool struct {
+	ctx       context.Context // stops new submissions
+	reqCtx    context.Context // cancels requests already sent
+	c         *client.Client
+	batchSize int
+	jobs      chan poolJob
+	batches   chan []poolJob
+	batcher   sync.WaitGroup
+	workers   sync.WaitGroup
+}
+
+type poolJob struct {
+	email string
+	done  chan<- validation
+}
+
+func newValidatorPool(ctx, reqCtx context.Context, c *client.Client, workers, batchSize int) *validatorPool {
+	if workers < 1 {
+		workers = 1
+	}
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	p := &validatorPool{
+		ctx:       ctx,
+		reqCtx:    reqCtx,
+		c:         c,
+		batchSize: batchSize,
+		jobs:      make(chan poolJob),
+		batches:   make(chan []poolJob),
+	}
+	p.batcher.Add(1)
+	go p.batch()
+	for i := 0; i < workers; i++ {
+		p.workers.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+// batch groups submitted jobs into batches, sending each one when it is
+// full or when batchLinger has passed since its first job arrived.
+func (p *validatorPool) batch() {
+	defer p.batcher.Done()
+	defer close(p.batches)
+
+	var pending []poolJob
+	var linger <-chan time.Time
+
+	flush := func() {
+		if len(pending) > 0 {
+			p.batches <- pending
+		}
+		pending = nil
+		linger = nil
+	}
+
+	for {
+		select {
+		case job, ok := <-p.jobs:
+			if !ok {
+				flush()
+				return
+			}
+			pending = append(pending, job)
+			if len(pending) == 1 {
+				linger = time.After(batchLinger)
+			}
+			if len(pending) >= p.batchSize {
+				flush()
+			}
+		case <-linger:
+			flush()
+		}
+	}
+}
+
+func (p *validatorPool) work() {
+	defer p.workers.Done()
+	for batch := range p.batches {
+		if p.ctx.Err() != nil {
+			// Requests already sent may finish, but after an interrupt
+			// nothing new goes out, not even a batch that was waiting.
+			p.fail(batch, context.Cause(p.ctx))
+			continue
+		}
+		if len(batch) == 1 {
+			p.validateOne(batch[0])
+			continue
+		}
+
+		emails := make([]string, len(batch))
+		for i, job := range batch {
+			emails[i] = job.email
+		}
+
+		results, err := p.c.ValidateBatch(p.reqCtx, emails)
+		for i, job := range batch {
+			switch {
+			case err != nil:
+				job.done <- validation{err: err}
+			case errors.Is(results[i].Err, client.ErrMissingResult):
+				// Give addresses the API skipped a second chance on their own.
+				p.validateOne(job)
+			default:
+				job.done <- validation{result: results[i].Result, err: results[i].Err}
+			}
+		}
+	}
+}
+
+func (p *validatorPool) validateOne(job poolJob) {
+	if p.ctx.Err() != nil {
+		p.fail([]poolJob{job}, context.Cause(p.ctx))
+		return
+	}
+	result, err := p.c.Validate(p.reqCtx, job.email)
+	job.done <- validation{result: result, err: err}
+}
+
+// fail completes jobs with err without sending them.
+func (p *validatorPool) fail(jobs []poolJob, err error) {
+	for _, job := range jobs {
+		job.done <- validation{err: err}
+	}
+}
+
+// Submit queues an email for validation and returns a channel that receives
+// exactly one validation. It blocks while every worker is busy and returns
+// false if the pool's context is cancelled first.
+func (p *validatorPool) Submit(email string) (<-chan validation, bool) {
+	done := make(chan validation, 1)
+	select {
+	case p.jobs <- poolJob{email: email, done: done}:
+		return done, true
+	case <-p.ctx.Done():
+		return nil, false
+	}
+}
+
+// Close stops accepting work, sends any partial batch, and waits for
+// in-flight validations to finish.
+func (p *validatorPool) Close() {
+	close(p.jobs)
+	p.batcher.Wait()
+	p.workers.Wait()
+}
+
+// queued is an input item waiting for its validation to complete.
+type queued struct {
+	row        []string // original CSV row; nil in stdin mode
+	email      string
+	original   string            // email before --fix-typos rewrite corrected it
+	correction string            // correction --fix-typos annotate found for email
+	key        string            // normalized email
+	repeat     bool              // email repeats an earlier row and reuses its result
+	first      bool              // no earlier row, even one written before a resume, has email
+	done       <-chan validation // nil when there is nothing to validate
+}
+
+// submitFunc queues a row for validation. An empty email passes the row
+// through without calling the API. It returns false once processing has
+// been aborted, after which the producer should stop.
+type submitFunc func(row []string, email string) bool
+
+// consumeFunc receives rows in input order. v is nil for rows that were
+// passed through without validation, and for repeats that have no result
+// because they are being dropped.
+type consumeFunc func(q queued, v *validation) error
+
+// bulkValidator holds what the validate modes need to turn emails into
+// results: the API client and the optional local cache in front of it.
+type bulkValidator struct {
+	client    *client.Client
+	norm      normalize.Options
+	cache     *cache.Cache // nil when caching is disabled
+	typos     *typoFixer   // nil unless --fix-typos is set
+	precheck  *prechecker  // nil when --no-precheck is set
+	refresh   bool         // skip cache reads but still store new results
+	dedupe    *deduper     // nil unless repeated addresses are validated once
+	workers   int
+	batchSize int
+}
+
+// address extracts and normalizes the address to validate from an input
+// value.
+func (bv *bulkValidator) address(input string) string {
+	return normalize.Address(input, bv.norm)
+}
+
+// lookup returns a validation for email that needs no API call: a local
+// precheck verdict, or a fresh cached result.
+func (bv *bulkValidator) lookup(email string) (validation, bool) {
+	if result := bv.precheck.check(email); result != nil {
+		return validation{result: result, local: true}, true
+	}
+	if bv.cache == nil || bv.refresh {
+		return validation{}, false
+	}
+	result, ok := bv.cache.Get(email)
+	if !ok {
+		return validation{}, false
+	}
+	return validation{result: result, cached: true}, true
+}
+
+// store saves a fresh API result to the cache.
+func (bv *bulkValidator) store(email string, v validation) {
+	if bv.cache == nil || v.cached || v.local || v.err != nil {
+		return
+	}
+	if err := bv.cache.Put(email, v.result); err != nil {
+		// The result is still good; only the cache write failed.
+		fmt.Fprintf(os.Stderr, "\nWarning: %s\n", err)
+	}
+}
+
+// validateOne validates a single email, consulting the precheck and the
+// cache first.
+func (bv *bulkValidator) validateOne(ctx context.Context, email string) validation {
+	if v, ok := bv.lookup(email); ok {
+		return v
+	}
+	if result := bv.precheck.checkMX(ctx, email); result != nil {
+		return validation{result: result, local: true}
+	}
+	result, err := bv.client.Validate(ctx, email)
+	v := validation{result: result, err: err}
+	bv.store(email, v)
+	return v
+}
+
+// resolveThenSubmit settles email with the DNS check if its domain can't
+// receive email, or else validates it on the pool.
+func (bv *bulkValidator) resolveThenSubmit(ctx context.Context, pool *validatorPool, email string) validation {
+	if result := bv.precheck.checkMX(ctx, email); result != nil {
+		return validation{result: result, local: true}
+	}
+	done, ok := pool.Submit(email)
+	if !ok {
+		return validation{err: context.Cause(ctx)}
+	}
+	return <-done
+}
+
+// run validates the rows produced by produce on a pool of workers and
+// hands each one to consume in the order it was submitted. Precheck
+// verdicts and cache hits skip the pool entirely, as do addresses on
+// domains the DNS check finds dead and repeats when deduplicating. At
+// most workers requests of up to batchSize emails run at once, and the
+// number of rows buffered ahead of the consumer is bounded by the same.
+//
+// When ctx is cancelled, no new rows are submitted or sent, but requests
+// already sent get interruptGrace to finish. Rows that complete in that time are
+// still consumed, up to the first one that fails, and run returns the
+// cause of the cancellation.
+func (bv *bulkValidator) run(ctx context.Context, produce func(submit submitFunc) error, consume consumeFunc) error {
+	parent := ctx
+
+	reqCtx, cancelReqs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelReqs()
+	stopGrace := context.AfterFunc(ctx, func() {
+		time.AfterFunc(interruptGrace, cancelReqs)
+	})
+	defer stopGrace()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers, batchSize := bv.workers, bv.batchSize
+	pool := newValidatorPool(ctx, reqCtx, bv.client, workers, batchSize)
+	queue := make(chan queued, workers*batchSize)
+
+	var produceErr error
+	go func() {
+		var lookups sync.WaitGroup
+		defer close(queue)
+		defer pool.Close()
+		defer lookups.Wait()
+
+		produceErr = produce(func(row []string, email string) bool {
+			q := queued{row: row, email: email}
+			bv.typos.apply(&q)
+			email = q.email
+			if email != "" {
+				q.key, q.repeat, q.first = bv.dedupe.see(email)
+			}
+			if email != "" && !q.repeat {
+				if v, hit := bv.lookup(email); hit {
+					done := make(chan validation, 1)
+					done <- v
+					q.done = done
+				} else if bv.precheck.resolves() {
+					// DNS lookups run beside the rows that follow, so a
+					// slow domain doesn't hold up the rest. Rows still
+					// reach the consumer in order.
+					done := make(chan validation, 1)
+					q.done = done
+					lookups.Add(1)
+					go func() {
+						defer lookups.Done()
+						done <- bv.resolveThenSubmit(ctx, pool, email)
+					}()
+				} else {
+					done, ok := pool.Submit(email)
+					if !ok {
+						return false
+					}
+					q.done = done
+				}
+			}
+			select {
+			case queue <- q:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	var consumeErr error
+	stopped := false
+	handle := func(q queued) {
+		if consumeErr != nil || stopped {
+			return
+		}
+
+		fail := func(err error) {
+			consumeErr = err
+			cancel()
+			cancelReqs()
+		}
+
+		var v *validation
+		switch {
+		case q.repeat:
+			var err error
+			if v, err = bv.dedupe.result(q.key); err != nil {
+				fail(err)
+				return
+			}
+		case q.done != nil:
+			res := <-q.done
+			bv.store(q.email, res)
+			if err := bv.dedupe.record(q.key, &res); err != nil {
+				fail(err)
+				return
+			}
+			v = &res
+		}
+		if parent.Err() != nil && v != nil && v.err != nil {
+			// After an interrupt, failures are usually cancelled requests.
+			// Stop here rather than record them as errors.
+			stopped = true
+			return
+		}
+		if err := consume(q, v); err != nil {
+			fail(err)
+		}
+	}
+
+	finished := false
+	for !finished {
+		select {
+		case q, ok := <-queue:
+			if !ok {
+				finished = true
+				break
+			}
+			handle(q)
+		case <-ctx.Done():
+			// Finish the rows already queued, but don't wait for the
+			// producer: it may be blocked reading input that never ends.
+			for drained := false; !drained; {
+				select {
+				case q, ok := <-queue:
+					if !ok {
+						drained = true
+						break
+					}
+					handle(q)
+				default:
+					drained = true
+				}
+			}
+			if consumeErr != nil {
+				return consumeErr
+			}
+			return context.Cause(parent)
+		}
+	}
+
+	if consumeErr != nil {
+		return consumeErr
+	}
+	if err := context.Cause(parent); err != nil {
+		return err
+	}
+	return produceErr
+}
@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+)
+
+// countingLimiter lets every request through and counts them.
+type countingLimiter struct {
+	waits atomic.Int32
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.waits.Add(1)
+	return ctx.Err()
+}
+
+// fakeAPI serves verify_inline, for one address in the query or a batch in
+// the body, answering with results from answer.
+type fakeAPI struct {
+	mu       sync.Mutex
+	requests [][]string // addresses of each request, in arrival order
+
+	answer func(emails []string) []client.ValidationResult
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var emails []string
+	if email := r.URL.Query().Get("email"); email != "" {
+		emails = []string{email}
+	} else {
+		var body struct {
+			Emails []string `json:"emails"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		emails = body.Emails
+	}
+	f.mu.Lock()
+	f.requests = append(f.requests, emails)
+	f.mu.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]any{"emails": f.answer(emails)})
+}
+
+// sent returns every address sent, in the order the requests arrived.
+func (f *fakeAPI) sent() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Concat(f.requests...)
+}
+
+// okResults answers every address with the state ok.
+func okResults(emails []string) []client.ValidationResult {
+	results := make([]client.ValidationResult, len(emails))
+	for i, email := range emails {
+		results[i] = client.ValidationResult{Email: email, State: "ok"}
+	}
+	return results
+}
+
+// newTestValidator returns a bulkValidator for a fake API, and the limiter
+// its requests go through.
+func newTestValidator(t *testing.T, api http.Handler, workers, batchSize int) (*bulkValidator, *countingLimiter) {
+	t.Helper()
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	limiter := &countingLimiter{}
+	c := client.New("test-key").
+		WithBaseURL(srv.URL).
+		WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1}).
+		WithLimiter(limiter)
+	return &bulkValidator{client: c, workers: workers, batchSize: batchSize}, limiter
+}
+
+// produceAll returns a producer submitting emails one per row.
+func produceAll(emails []string) func(submitFunc) error {
+	return func(submit submitFunc) error {
+		for _, email := range emails {
+			if !submit([]string{email}, email) {
+				break
+			}
+		}
+		return nil
+	}
+}
+
+func TestRunKeepsOrder(t *testing.T) {
+	api := &fakeAPI{answer: func(emails []string) []client.ValidationResult {
+		// Finish requests out of order, and answer batches back to front.
+		time.Sleep(time.Duration(rand.Intn(20)) * time.Millisecond)
+		results := okResults(emails)
+		slices.Reverse(results)
+		return results
+	}}
+	bv, limiter := newTestValidator(t, api, 4, 3)
+
+	var emails []string
+	for i := 0; i < 60; i++ {
+		emails = append(emails, fmt.Sprintf("user%d@example.com", i))
+	}
+	emails = append(emails, "") // passed through without validation
+
+	var got []string
+	err := bv.run(context.Background(), produceAll(emails), func(q queued, v *validation) error {
+		switch {
+		case q.email == "":
+			if v != nil {
+				t.Errorf("row without an email got %+v", v)
+			}
+		case v == nil || v.err != nil:
+			t.Errorf("%s: got %+v", q.email, v)
+		case v.result.Email != q.email:
+			t.Errorf("%s: got the result for %s", q.email, v.result.Email)
+		}
+		got = append(got, q.email)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("run = %v", err)
+	}
+	if !slices.Equal(got, emails) {
+		t.Errorf("rows consumed out of order:\n got %q\nwant %q", got, emails)
+	}
+	if n := len(api.sent()); n != 60 {
+		t.Errorf("sent %d addresses, want 60", n)
+	}
+	if n := limiter.waits.Load(); n < 20 {
+		t.Errorf("made %d requests, want at least 20 batches of up to 3", n)
+	}
+}
+
+func TestRunMissingResultFallback(t *testing.T) {
+	api := &fakeAPI{answer: func(emails []string) []client.ValidationResult {
+		results := okResults(emails)
+		if len(emails) > 1 {
+			// Leave skipped addresses out of batch responses.
+			results = slices.DeleteFunc(results, func(r client.ValidationResult) bool {
+				return strings.HasPrefix(r.Email, "skip")
+			})
+		}
+		return results
+	}}
+	bv, _ := newTestValidator(t, api, 2, 10)
+
+	emails := []string{"a@example.com", "skip1@example.com", "b@example.com", "skip2@example.com"}
+	var got []string
+	err := bv.run(context.Background(), produceAll(emails), func(q queued, v *validation) error {
+		if v == nil || v.err != nil || v.result.Email != q.email {
+			t.Errorf("%s: got %+v", q.email, v)
+		}
+		got = append(got, q.email)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("run = %v", err)
+	}
+	if !slices.Equal(got, emails) {
+		t.Errorf("consumed %q, want %q", got, emails)
+	}
+
+	var singles []string
+	for _, r := range api.requests {
+		if len(r) == 1 {
+			singles = append(singles, r[0])
+		}
+	}
+	slices.Sort(singles)
+	if want := []string{"skip1@example.com", "skip2@example.com"}; !slices.Equal(singles, want) {
+		t.Errorf("retried %q on their own, want %q", singles, want)
+	}
+}
+
+func TestRunDedupe(t *testing.T) {
+	api := &fakeAPI{answer: okResults}
+	bv, _ := newTestValidator(t, api, 3, 2)
+	bv.dedupe = newDeduper(false)
+	defer bv.dedupe.close()
+
+	emails := []string{"a@example.com", "b@example.com", "a@EXAMPLE.com", "c@example.com", "b@example.com", "a@example.com"}
+	var repeats []string
+	err := bv.run(context.Background(), produceAll(emails), func(q queued, v *validation) error {
+		if v == nil || v.err != nil || !strings.EqualFold(v.result.Email, q.email) || v.cached || v.local {
+			t.Errorf("%s: got %+v", q.email, v)
+		}
+		if q.repeat {
+			repeats = append(repeats, q.email)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("run = %v", err)
+	}
+
+	sent := api.sent()
+	slices.Sort(sent)
+	if want := []string{"a@example.com", "b@example.com", "c@example.com"}; !slices.Equal(sent, want) {
+		t.Errorf("sent %q, want each address once: %q", sent, want)
+	}
+	if want := []string{"a@EXAMPLE.com", "b@example.com", "a@example.com"}; !slices.Equal(repeats, want) {
+		t.Errorf("repeats = %q, want %q", repeats, want)
+	}
+}
+
+func TestRunInterrupt(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	api := &fakeAPI{answer: func(emails []string) []client.ValidationResult {
+		once.Do(func() { close(started) })
+		<-release
+		return okResults(emails)
+	}}
+	bv, limiter := newTestValidator(t, api, 1, 1)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	// The producer never runs out, like `tail -f`.
+	produce := func(submit submitFunc) error {
+		for i := 0; ; i++ {
+			email := fmt.Sprintf("user%d@example.com", i)
+			if !submit([]string{email}, email) {
+				return nil
+			}
+		}
+	}
+
+	var got []string
+	errc := make(chan error, 1)
+	go func() {
+		errc <- bv.run(ctx, produce, func(q queued, v *validation) error {
+			if v == nil || v.err != nil {
+				t.Errorf("%s: got %+v", q.email, v)
+			}
+			got = append(got, q.email)
+			return nil
+		})
+	}()
+
+	// Interrupt while the first request is in flight, with more rows
+	// waiting for the worker, then let the request finish within the
+	// grace period.
+	<-started
+	cancel(errInterrupted)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	select {
+	case err := <-errc:
+		if err != errInterrupted {
+			t.Errorf("run = %v, want %v", err, errInterrupted)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not return after the interrupt")
+	}
+	if want := []string{"user0@example.com"}; !slices.Equal(got, want) {
+		t.Errorf("consumed %q, want only the request in flight: %q", got, want)
+	}
+	if n := limiter.waits.Load(); n != 1 {
+		t.Errorf("made %d requests, want no new ones after the interrupt", n)
+	}
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/address"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/dnscheck"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/lists"
+)
+
+// Precheck levels for --precheck.
+const (
+	precheckSyntax = "syntax" // syntax only
+	precheckLocal  = "local"  // syntax, disposable domains and role names
+)
+
+// mxLookupTimeout bounds the DNS lookups for one domain with --check-mx.
+// A domain whose lookups time out is left to the API.
+const mxLookupTimeout = 5 * time.Second
+
+// prechecker settles addresses locally when the API's answer is certain,
+// so they don't cost a credit. A nil prechecker checks nothing.
+type prechecker struct {
+	disposable *lists.List       // nil unless --precheck local
+	roles      *lists.List       // nil unless --precheck local
+	mx         *dnscheck.Checker // nil unless --check-mx
+}
+
+// newPrechecker returns a prechecker for a --precheck level, loading the
+// lists it needs.
+func newPrechecker(level string) (*prechecker, error) {
+	switch level {
+	case precheckSyntax:
+		return &prechecker{}, nil
+	case precheckLocal:
+		disposable, err := lists.Load(lists.Disposable)
+		if err != nil {
+			return nil, err
+		}
+		roles, err := lists.Load(lists.Roles)
+		if err != nil {
+			return nil, err
+		}
+		return &prechecker{disposable: disposable, roles: roles}, nil
+	default:
+		return nil, usageErrorf("--precheck must be %s or %s", precheckSyntax, precheckLocal)
+	}
+}
+
+// check returns a local result for email, or nil if it needs the API.
+// Addresses that are not syntactically valid are email_invalid with the
+// sub-state failed_syntax_check, as the API would report them. With the
+// lists loaded, addresses on disposable domains and role accounts are ok
+// with the sub-states is_disposable and is_role, as the API reports them
+// too: they can receive email, but may not be worth sending to.
+func (p *prechecker) check(email string) *client.ValidationResult {
+	if p == nil {
+		return nil
+	}
+	result := func(domain, state, subState, source string) *client.ValidationResult {
+		return &client.ValidationResult{
+			Email:    email,
+			Domain:   strings.ToLower(domain),
+			State:    state,
+			SubState: subState,
+			Source:   source,
+		}
+	}
+
+	addr, err := address.Parse(email)
+	if err != nil {
+		domain := ""
+		if at := strings.LastIndexByte(email, '@'); at >= 0 {
+			domain = email[at+1:]
+		}
+		return result(domain, "email_invalid", "failed_syntax_check", client.SourceSyntax)
+	}
+	if p.disposable != nil && !addr.Literal && p.disposable.ContainsDomain(addr.ASCIIDomain) {
+		return result(addr.Domain, "ok", "is_disposable", client.SourceDisposable)
+	}
+	if p.roles != nil && p.roles.Contains(roleName(addr.Local)) {
+		return result(addr.Domain, "ok", "is_role", client.SourceRole)
+	}
+	return nil
+}
+
+// mxCanary is a domain known to receive email, looked up before turning
+// on the DNS check. If it doesn't come back live, lookups can't be trusted
+// here, as with a resolver that answers every name with NXDOMAIN, and
+// every domain would look dead.
+const mxCanary = "gmail.com"
+
+// enableMX turns on the DNS check of checkMX, using resolver. It reports
+// false, leaving the check off, if resolver fails to find mxCanary.
+func (p *prechecker) enableMX(ctx context.Context, resolver dnscheck.Resolver) bool {
+	mx := dnscheck.New(resolver, mxLookupTimeout)
+	if mx.Check(ctx, mxCanary) != dnscheck.Live {
+		return false
+	}
+	p.mx = mx
+	return true
+}
+
+// resolves reports whether checkMX looks anything up. Unlike check, it
+// waits on the network, so bulk runs do it off the input's path.
+func (p *prechecker) resolves() bool {
+	return p != nil && p.mx != nil
+}
+
+// checkMX returns a local result for an address on a domain that can't
+// receive email, or nil if the domain may receive it. Such addresses are
+// email_invalid with the sub-state failed_mx_check, as the API would
+// report them. Each domain is looked up once per run. email must have
+// passed check.
+func (p *prechecker) checkMX(ctx context.Context, email string) *client.ValidationResult {
+	if !p.resolves() {
+		return nil
+	}
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 || strings.HasPrefix(email[at+1:], "[") {
+		return nil
+	}
+	domain := email[at+1:]
+	if p.mx.Check(ctx, domain) != dnscheck.Dead {
+		return nil
+	}
+	return &client.ValidationResult{
+		Email:    email,
+		Domain:   strings.ToLower(domain),
+		State:    "email_invalid",
+		SubState: "failed_mx_check",
+		Source:   client.SourceDNS,
+	}
+}
+
+// deadDomains returns the number of domains checkMX found can't receive
+// email.
+func (p *prechecker) deadDomains() int {
+	if !p.resolves() {
+		return 0
+	}
+	return p.mx.Dead()
+}
+
+// roleName returns the part of a local part to look up in the role list:
+// everything before a +tag. Quoted local parts are never role names.
+func roleName(local string) string {
+	if strings.HasPrefix(local, `"`) {
+		return ""
+	}
+	name, _, _ := strings.Cut(local, "+")
+	return name
+}
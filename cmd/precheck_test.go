@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+)
+
+func TestPrecheckCheck(t *testing.T) {
+	// Use the embedded lists, not updated copies.
+	t.Setenv("HOME", t.TempDir())
+	local, err := newPrechecker(precheckLocal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	syntax, err := newPrechecker(precheckSyntax)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		email    string
+		p        *prechecker
+		state    string // "" when the address needs the API
+		subState string
+		source   string
+		domain   string
+	}{
+		{"jo smith@Example.com", syntax, "email_invalid", "failed_syntax_check", client.SourceSyntax, "example.com"},
+		{"no-at-sign", syntax, "email_invalid", "failed_syntax_check", client.SourceSyntax, ""},
+		{"jo@example.com", syntax, "", "", "", ""},
+		{"info@example.com", syntax, "", "", "", ""},
+
+		{"jo@Mailinator.com", local, "ok", "is_disposable", client.SourceDisposable, "mailinator.com"},
+		{"jo@inbox.mailinator.com", local, "ok", "is_disposable", client.SourceDisposable, "inbox.mailinator.com"},
+		{"Info@example.com", local, "ok", "is_role", client.SourceRole, "example.com"},
+		{"info+news@example.com", local, "ok", "is_role", client.SourceRole, "example.com"},
+		{`"info"@example.com`, local, "", "", "", ""},
+		{"information@example.com", local, "", "", "", ""},
+		{"jo@example.com", local, "", "", "", ""},
+		{"jo@@example.com", local, "email_invalid", "failed_syntax_check", client.SourceSyntax, "example.com"},
+
+		{"jo smith@example.com", nil, "", "", "", ""},
+	}
+	for _, tt := range tests {
+		got := tt.p.check(tt.email)
+		if tt.state == "" {
+			if got != nil {
+				t.Errorf("check(%q) = %+v, want nil", tt.email, got)
+			}
+			continue
+		}
+		if got == nil {
+			t.Errorf("check(%q) = nil, want %s / %s", tt.email, tt.state, tt.subState)
+			continue
+		}
+		if got.Email != tt.email || got.State != tt.state || got.SubState != tt.subState || got.Source != tt.source || got.Domain != tt.domain {
+			t.Errorf("check(%q) = %+v, want %s / %s from %s for domain %q", tt.email, got, tt.state, tt.subState, tt.source, tt.domain)
+		}
+	}
+}
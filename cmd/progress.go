@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+// rowCountLimit is the input size above which file mode tracks progress by
+// bytes read instead of counting rows up front.
+const rowCountLimit = 256 << 20
+
+// newProgressBar returns the progress bar used by bulk commands.
+func newProgressBar(total int, description string) *progressbar.ProgressBar {
+	return progressbar.NewOptions(total,
+		progressbar.OptionSetDescription(description),
+		progressbar.OptionSetWriter(os.Stderr),
+		progressbar.OptionShowCount(),
+		progressbar.OptionSetWidth(40),
+		progressbar.OptionThrottle(100*1e6), // 100ms as nanoseconds
+		progressbar.OptionClearOnFinish(),
+		progressbar.OptionSetPredictTime(true),
+	)
+}
+
+// csvProgress drives the progress bar for a streamed CSV file. Small files
+// are counted in a cheap pre-pass and progress by row; large ones progress
+// by the byte offset of the last row read.
+type csvProgress struct {
+	bar     *progressbar.ProgressBar
+	byBytes bool
+	start   int64        // offset of the first data row
+	offset  atomic.Int64 // offset after the last row read
+}
+
+// newCSVProgress sizes a progress bar for the CSV open as f, whose data
+// rows start at headerEnd.
+func newCSVProgress(f *os.File, path string, headerEnd int64) (*csvProgress, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("could not read file info: %w", err)
+	}
+
+	p := &csvProgress{start: headerEnd}
+	switch {
+	case !fi.Mode().IsRegular():
+		// Pipes can't be read twice and have no size: show a spinner.
+		p.bar = newProgressBar(-1, "Validating")
+	case fi.Size() > rowCountLimit:
+		p.byBytes = true
+		p.bar = progressbar.NewOptions64(fi.Size()-headerEnd,
+			progressbar.OptionSetDescription("Validating"),
+			progressbar.OptionSetWriter(os.Stderr),
+			progressbar.OptionShowBytes(true),
+			progressbar.OptionSetWidth(40),
+			progressbar.OptionThrottle(100*1e6), // 100ms as nanoseconds
+			progressbar.OptionClearOnFinish(),
+			progressbar.OptionSetPredictTime(true),
+		)
+	default:
+		rows, err := countCSVRows(path)
+		if err != nil {
+			return nil, err
+		}
+		p.bar = newProgressBar(rows, "Validating")
+	}
+	return p, nil
+}
+
+// read records the input offset after a row was read. It may be called
+// from a different goroutine than written.
+func (p *csvProgress) read(offset int64) {
+	p.offset.Store(offset)
+}
+
+// skipped advances the bar past rows validated by an earlier run.
+func (p *csvProgress) skipped(rows int, offset int64) {
+	p.read(offset)
+	if p.byBytes {
+		_ = p.bar.Set64(offset - p.start)
+		return
+	}
+	_ = p.bar.Add(rows)
+}
+
+// written advances the bar after a row was written.
+func (p *csvProgress) written() {
+	if p.byBytes {
+		_ = p.bar.Set64(p.offset.Load() - p.start)
+		return
+	}
+	_ = p.bar.Add(1)
+}
+
+func (p *csvProgress) finish() {
+	_ = p.bar.Finish()
+}
+
+// countCSVRows counts the data rows of a CSV file without keeping them.
+func countCSVRows(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("could not open file: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.ReuseRecord = true
+
+	rows := -1 // don't count the header
+	for {
+		_, err := reader.Read()
+		if err == io.EOF {
+			return max(rows, 0), nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error reading CSV: %w", err)
+		}
+		rows++
+	}
+}
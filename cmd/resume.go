@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/checkpoint"
+)
+
+// checkpointInterval is how often file mode updates its checkpoint.
+const checkpointInterval = time.Second
+
+// checkpointer keeps a run's checkpoint up to date as rows are written.
+// A nil checkpointer does nothing, for inputs that can't be checkpointed.
+type checkpointer struct {
+	cp    *checkpoint.Checkpoint
+	saved time.Time
+}
+
+// wrote records one more written row, saving at most once per interval.
+func (c *checkpointer) wrote() error {
+	if c == nil {
+		return nil
+	}
+	c.cp.RowsWritten++
+	if time.Since(c.saved) < checkpointInterval {
+		return nil
+	}
+	c.saved = time.Now()
+	return c.cp.Save()
+}
+
+// done removes the checkpoint after a run completes.
+func (c *checkpointer) done() error {
+	if c == nil {
+		return nil
+	}
+	return checkpoint.Remove(c.cp.Output)
+}
+
+// isRegularFile reports whether path is a regular file, which can be hashed
+// and re-read. Pipes such as /dev/stdin are not.
+func isRegularFile(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// resumeOutput reopens a partial output file for appending. It verifies
+// the header, counts the complete rows already written (tallying their
+// states into counts, if the layout includes the state), and truncates any
+// partially written final row.
+func resumeOutput(outPath string, layout *csvLayout, counts *tally) (*os.File, int, error) {
+	f, err := os.OpenFile(outPath, os.O_RDWR, 0)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not open output file to resume: %w", err)
+	}
+
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil || !slices.Equal(header, layout.header) {
+		f.Close()
+		return nil, 0, fmt.Errorf("%s does not have the expected header — start over without --resume", outPath)
+	}
+
+	stateIdx := layout.stateColumn()
+	rows := 0
+	end := reader.InputOffset()
+	for {
+		row, readErr := reader.Read()
+		if readErr == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(readErr, &parseErr) {
+			// A row cut off mid-write; it will be written again.
+			break
+		}
+		if readErr != nil {
+			f.Close()
+			return nil, 0, fmt.Errorf("could not read output file: %w", readErr)
+		}
+		complete, err := endsLine(f, reader.InputOffset())
+		if err != nil {
+			f.Close()
+			return nil, 0, fmt.Errorf("could not read output file: %w", err)
+		}
+		if !complete {
+			// Cut off after its last field started, so it parsed.
+			break
+		}
+
+		if stateIdx >= 0 {
+			if state := row[stateIdx]; state != "" && state != "error" {
+				counts.add(state)
+			}
+		}
+		rows++
+		end = reader.InputOffset()
+	}
+
+	if err := f.Truncate(end); err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("could not truncate output file: %w", err)
+	}
+	if _, err := f.Seek(end, io.SeekStart); err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("could not seek output file: %w", err)
+	}
+	return f, rows, nil
+}
+
+// endsLine reports whether the byte before offset is a newline, meaning a
+// row ending there was written in full.
+func endsLine(f *os.File, offset int64) (bool, error) {
+	b := make([]byte, 1)
+	if _, err := f.ReadAt(b, offset-1); err != nil {
+		return false, err
+	}
+	return b[0] == '\n', nil
+}
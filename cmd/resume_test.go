@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/checkpoint"
+)
+
+func TestResumeOutput(t *testing.T) {
+	layout, err := newCSVLayout([]string{"email"}, []string{"state"}, "truelist_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const written = "email,truelist_state\n" +
+		"a@example.com,ok\n" +
+		"b@example.com,email_invalid\n" +
+		"c@example.com,error\n"
+
+	tests := []struct {
+		name, tail string
+	}{
+		{"complete", ""},
+		{"short row", "d@example.com,o"},
+		{"open quote", `"d@exam`},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "out.csv")
+		if err := os.WriteFile(path, []byte(written+tt.tail), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		var counts tally
+		f, rows, err := resumeOutput(path, layout, &counts)
+		if err != nil {
+			t.Errorf("%s: resumeOutput = %v", tt.name, err)
+			continue
+		}
+		// Later rows are appended after the complete ones.
+		io.WriteString(f, "e@example.com,ok\n")
+		f.Close()
+
+		if rows != 3 {
+			t.Errorf("%s: %d rows written, want 3", tt.name, rows)
+		}
+		if counts.ok != 1 || counts.invalid != 1 || counts.unknown != 0 {
+			t.Errorf("%s: counts = %+v, want the ok and email_invalid rows", tt.name, counts)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := written + "e@example.com,ok\n"; string(data) != want {
+			t.Errorf("%s: output =\n%s\nwant\n%s", tt.name, data, want)
+		}
+	}
+}
+
+func TestResumeOutputHeader(t *testing.T) {
+	layout, err := newCSVLayout([]string{"email"}, []string{"state", "sub_state"}, "truelist_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "out.csv")
+	if err := os.WriteFile(path, []byte("email,truelist_state\na@example.com,ok\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := resumeOutput(path, layout, &tally{}); err == nil {
+		t.Error("resumeOutput with a different header succeeded")
+	}
+	if _, _, err := resumeOutput(filepath.Join(t.TempDir(), "missing.csv"), layout, &tally{}); err == nil {
+		t.Error("resumeOutput of a missing file succeeded")
+	}
+}
+
+func TestCheckpointer(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.csv")
+	output := filepath.Join(dir, "out.csv")
+	if err := os.WriteFile(input, []byte("email\na@example.com\nb@example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cp, err := checkpoint.New(input, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.RowsWritten = 5 // rows an interrupted run wrote
+	c := &checkpointer{cp: cp}
+
+	// The first row saves at once; the next waits for the interval.
+	for range 2 {
+		if err := c.wrote(); err != nil {
+			t.Fatalf("wrote = %v", err)
+		}
+	}
+	saved, err := checkpoint.Load(output)
+	if err != nil {
+		t.Fatalf("Load = %v", err)
+	}
+	if saved.RowsWritten != 6 || cp.RowsWritten != 7 {
+		t.Errorf("saved %d rows written, counted %d, want 6 and 7", saved.RowsWritten, cp.RowsWritten)
+	}
+
+	if err := c.done(); err != nil {
+		t.Fatalf("done = %v", err)
+	}
+	if _, err := checkpoint.Load(output); !errors.Is(err, checkpoint.ErrNotFound) {
+		t.Errorf("Load after done = %v, want ErrNotFound", err)
+	}
+
+	var none *checkpointer
+	if none.wrote() != nil || none.done() != nil {
+		t.Error("a nil checkpointer should do nothing")
+	}
+}
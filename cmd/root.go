@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -19,9 +24,46 @@ Get started by setting your API key:
 	SilenceErrors: true,
 }
 
-// Execute runs the root command.
+func init() {
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		output.PrintError(os.Stderr, err)
+		return usageError{err: err}
+	})
+}
+
+// Execute runs the root command and exits with a code describing the
+// outcome (see exit.go).
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	ctx, stop := interruptContext()
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		stop()
+		os.Exit(exitCode(err))
+	}
+}
+
+// interruptContext returns a context that is cancelled with errInterrupted
+// on the first SIGINT or SIGTERM, giving commands a chance to flush partial
+// output. A second signal exits immediately.
+func interruptContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigs
+		fmt.Fprintln(os.Stderr, "\nInterrupted — finishing in-flight requests (press Ctrl-C again to quit now)")
+		cancel(errInterrupted)
+
+		<-sigs
+		os.Exit(exitInterrupted)
+	}()
+
+	stop := func() {
+		signal.Stop(sigs)
+		cancel(nil)
 	}
+	return ctx, stop
 }
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// splitStates are the files --split-by state writes on every run, even if
+// some of them end up with only a header.
+var splitStates = []string{"ok", "email_invalid", "accept_all", "unknown", "error"}
+
+// splitOutput writes each row to <base>_<part><ext>, where the part is the
+// row's state or sub-state. Files are created as rows need them.
+type splitOutput struct {
+	by     string // "state" or "sub_state"
+	base   string
+	ext    string
+	layout *csvLayout
+	files  map[string]*csvFile
+	order  []string
+
+	// created is called after a new part file is created, so the run's
+	// checkpoint can record it.
+	created func(part string) error
+}
+
+// newSplitOutput returns a split output named after outPath: rows of
+// contacts.csv split by state go to contacts_ok.csv and so on.
+func newSplitOutput(by, outPath string, layout *csvLayout) *splitOutput {
+	ext := filepath.Ext(outPath)
+	return &splitOutput{
+		by:     by,
+		base:   strings.TrimSuffix(outPath, ext),
+		ext:    ext,
+		layout: layout,
+		files:  make(map[string]*csvFile),
+	}
+}
+
+// part returns the name of the file a row belongs in.
+func (s *splitOutput) part(v *validation) string {
+	switch {
+	case v == nil:
+		return "no_email"
+	case v.err != nil:
+		return "error"
+	case s.by == "sub_state":
+		if part := fileSafe(v.result.SubState); part != "" {
+			return part
+		}
+		return "unknown"
+	}
+
+	switch state := strings.ToLower(v.result.State); state {
+	case "ok", "email_invalid", "accept_all":
+		return state
+	default:
+		return "unknown"
+	}
+}
+
+func (s *splitOutput) path(part string) string {
+	return s.base + "_" + part + s.ext
+}
+
+// file returns the open file for a part, creating it if needed.
+func (s *splitOutput) file(part string) (*csvFile, error) {
+	if f, ok := s.files[part]; ok {
+		return f, nil
+	}
+
+	f, err := createCSV(s.path(part), s.layout)
+	if err != nil {
+		return nil, err
+	}
+	s.files[part] = f
+	s.order = append(s.order, part)
+
+	if s.created != nil {
+		if err := s.created(part); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// create creates the files a new run always writes.
+func (s *splitOutput) create() error {
+	if s.by != "state" {
+		return nil
+	}
+	for _, part := range splitStates {
+		if _, err := s.file(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resume reopens the part files of an interrupted run and returns the
+// number of rows they hold in total. Each input row went to exactly one
+// file, so that is also the number of input rows already written.
+func (s *splitOutput) resume(parts []string, counts *tally) (int, error) {
+	total := 0
+	for _, part := range parts {
+		f, rows, err := resumeCSV(s.path(part), s.layout, counts)
+		if err != nil {
+			return 0, err
+		}
+		s.files[part] = f
+		s.order = append(s.order, part)
+		total += rows
+	}
+	return total, nil
+}
+
+func (s *splitOutput) write(q queued, v *validation) error {
+	f, err := s.file(s.part(v))
+	if err != nil {
+		return err
+	}
+	return f.write(q, v)
+}
+
+func (s *splitOutput) paths() []string {
+	paths := make([]string, len(s.order))
+	for i, part := range s.order {
+		paths[i] = s.files[part].path
+	}
+	return paths
+}
+
+func (s *splitOutput) close() error {
+	var errs []error
+	for _, f := range s.files {
+		errs = append(errs, f.close())
+	}
+	return errors.Join(errs...)
+}
+
+// fileSafe lowercases s and replaces anything but letters, digits, '-'
+// and '_' with '_', for use in a file name.
+func fileSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, strings.ToLower(strings.TrimSpace(s)))
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/lists"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/normalize"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/output"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/typo"
+	"github.com/spf13/cobra"
+)
+
+// --fix-typos modes.
+const (
+	fixTyposAnnotate = "annotate" // add the correction next to the row
+	fixTyposRewrite  = "rewrite"  // validate the corrected address instead
+)
+
+var flagSuggestJSON bool
+
+func init() {
+	suggestCmd.Flags().BoolVar(&flagSuggestJSON, "json", false, "Output suggestions as JSON")
+	rootCmd.AddCommand(suggestCmd)
+}
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest [email|domain...]",
+	Short: "Suggest corrections for misspelled provider domains",
+	Long: `Suggest corrections for addresses or domains that look like misspellings of a
+popular email provider, such as gmial.con for gmail.com. This runs offline
+and costs no credits. Addresses are read from stdin if none are given.
+
+The providers come from the "providers" list (see "truelist lists").
+
+Example:
+  truelist suggest jo@gmial.con hotmial.com
+  cat emails.txt | truelist suggest --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		corrector, err := newCorrector()
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		inputs := args
+		if len(args) == 0 {
+			stat, _ := os.Stdin.Stat()
+			if (stat.Mode() & os.ModeCharDevice) != 0 {
+				err := usageErrorf("no email provided — pass addresses or domains as arguments, or pipe them from stdin")
+				output.PrintError(os.Stderr, err)
+				return err
+			}
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() {
+				inputs = append(inputs, scanner.Text())
+			}
+			if err := scanner.Err(); err != nil {
+				output.PrintError(os.Stderr, err)
+				return err
+			}
+		}
+
+		var suggestions []output.Suggestion
+		for _, input := range inputs {
+			input = normalize.Address(input, normalize.Options{})
+			if input == "" {
+				continue
+			}
+			s := output.Suggestion{Input: input}
+			if fixed, ok := suggest(corrector, input); ok {
+				s.Suggestion = &fixed
+			}
+			if flagSuggestJSON {
+				suggestions = append(suggestions, s)
+			} else {
+				output.PrintSuggestion(os.Stdout, s)
+			}
+		}
+		if flagSuggestJSON {
+			if suggestions == nil {
+				suggestions = []output.Suggestion{}
+			}
+			return output.PrintSuggestionsJSON(os.Stdout, suggestions)
+		}
+		return nil
+	},
+}
+
+// newCorrector returns a typo corrector for the providers list.
+func newCorrector() (*typo.Corrector, error) {
+	providers, err := lists.Load(lists.Providers)
+	if err != nil {
+		return nil, err
+	}
+	return typo.New(providers.Entries()), nil
+}
+
+// suggest corrects an address, or a bare domain.
+func suggest(c *typo.Corrector, input string) (string, bool) {
+	if strings.Contains(input, "@") {
+		return c.SuggestAddress(input)
+	}
+	return c.Suggest(input)
+}
+
+// typoFixer applies --fix-typos to the addresses of a file run. A nil
+// typoFixer changes nothing.
+type typoFixer struct {
+	corrector *typo.Corrector
+	rewrite   bool
+}
+
+// newTypoFixer returns a typoFixer for a --fix-typos mode.
+func newTypoFixer(mode string) (*typoFixer, error) {
+	if mode != fixTyposAnnotate && mode != fixTyposRewrite {
+		return nil, usageErrorf("--fix-typos must be %s or %s", fixTyposAnnotate, fixTyposRewrite)
+	}
+	corrector, err := newCorrector()
+	if err != nil {
+		return nil, err
+	}
+	return &typoFixer{corrector: corrector, rewrite: mode == fixTyposRewrite}, nil
+}
+
+// apply looks for a correction of a queued address. When rewriting, the
+// correction replaces the address, which is kept as q.original; otherwise
+// it is recorded as q.correction.
+func (t *typoFixer) apply(q *queued) {
+	if t == nil || q.email == "" {
+		return
+	}
+	fixed, ok := t.corrector.SuggestAddress(q.email)
+	switch {
+	case !ok:
+	case t.rewrite:
+		q.original, q.email = q.email, fixed
+	default:
+		q.correction = fixed
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"io"
+	"strings"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/output"
+)
+
+// tally counts validation results by state for the summary.
+type tally struct {
+	ok, invalid, acceptAll, unknown int
+	cached                          int
+	local                           int // settled by a precheck
+	dns                             int // settled by the DNS check, part of local
+	deadDomains                     int // domains the DNS check found dead
+	rejected                        int // filtered out by --where
+	failed                          int // rows that failed to validate
+	firstFailure                    error
+	typos                           int // rows --fix-typos found a correction for
+	typosFixed                      bool
+
+	// rows and unique count input rows with an address and the distinct
+	// addresses among them, when a run deduplicates.
+	rows, unique int
+
+	// files lists the output files, when a run writes more than one.
+	files []string
+}
+
+// add counts one result. States other than ok, email_invalid and
+// accept_all are counted as unknown.
+func (t *tally) add(state string) {
+	switch strings.ToLower(state) {
+	case "ok":
+		t.ok++
+	case "email_invalid":
+		t.invalid++
+	case "accept_all":
+		t.acceptAll++
+	default:
+		t.unknown++
+	}
+}
+
+// addFailure counts a row that failed to validate.
+func (t *tally) addFailure(err error) {
+	if t.failed == 0 {
+		t.firstFailure = err
+	}
+	t.failed++
+}
+
+// addRow counts an input row of file mode. Rows without an address are
+// not counted; first says whether no earlier row has the address.
+func (t *tally) addRow(email string, first bool) {
+	if email == "" {
+		return
+	}
+	t.rows++
+	if first {
+		t.unique++
+	}
+}
+
+// count returns the number of results counted in a state bucket.
+func (t *tally) count(state string) int {
+	switch state {
+	case "ok":
+		return t.ok
+	case "email_invalid":
+		return t.invalid
+	case "accept_all":
+		return t.acceptAll
+	default:
+		return t.unknown
+	}
+}
+
+// addValidation counts a successful validation, including whether it was
+// served from the cache or settled by a precheck.
+func (t *tally) addValidation(v *validation) {
+	t.add(v.result.State)
+	switch {
+	case v.cached:
+		t.cached++
+	case v.local:
+		t.local++
+		if v.result.Source == client.SourceDNS {
+			t.dns++
+		}
+	}
+}
+
+func (t *tally) summary() output.Summary {
+	return output.Summary{
+		Total:       t.ok + t.invalid + t.acceptAll + t.unknown,
+		OK:          t.ok,
+		Invalid:     t.invalid,
+		AcceptAll:   t.acceptAll,
+		Unknown:     t.unknown,
+		Cached:      t.cached,
+		Local:       t.local,
+		DNS:         t.dns,
+		DeadDomains: t.deadDomains,
+		Rejected:    t.rejected,
+		Failed:      t.failed,
+		Typos:       t.typos,
+		TyposFixed:  t.typosFixed,
+		Rows:        t.rows,
+		Unique:      t.unique,
+		Files:       t.files,
+	}
+}
+
+// print writes the summary block.
+func (t *tally) print(w io.Writer) {
+	output.PrintSummary(w, t.summary())
+}
@@ -5,25 +5,50 @@ import (
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
+	"text/template"
 
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/checkpoint"
 	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
-	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/config"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/filter"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/normalize"
 	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/output"
-	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 )
 
 var (
-	flagFile       string
-	flagOutput     string
-	flagColumn     string
-	flagJSON       bool
-	flagQuiet      bool
+	flagFile        string
+	flagOutput      string
+	flagColumn      string
+	flagJSON        bool
+	flagJSONL       bool
+	flagQuiet       bool
+	flagConcurrency int
+	flagBatchSize   int
+	flagNoCache     bool
+	flagRefresh     bool
+	flagNoPrecheck  bool
+	flagPrecheck    string
+	flagResume      bool
+	flagDedupeOut   bool
+	flagSplitBy     string
+	flagFields      []string
+	flagFormat      string
+	flagTable       bool
+	flagColumns     []string
+	flagColPrefix   string
+	flagWhere       string
+	flagRejects     string
+	flagFailOn      []string
+	flagFoldAliases bool
+	flagFixTypos    string
+	flagCheckMX     bool
 )
 
 func init() {
@@ -31,97 +56,313 @@ func init() {
 	validateCmd.Flags().StringVarP(&flagOutput, "output", "o", "", "Output file path (default: <input>_validated.csv)")
 	validateCmd.Flags().StringVarP(&flagColumn, "column", "c", "", "Name of the email column in the CSV")
 	validateCmd.Flags().BoolVar(&flagJSON, "json", false, "Output results as JSON")
+	validateCmd.Flags().BoolVar(&flagJSONL, "jsonl", false, "Output one line of JSON per result as soon as it is ready")
+	validateCmd.Flags().StringVar(&flagFormat, "format", "", `Format each result with a Go template, e.g. '{{.Email}}\t{{.State}}'`)
+	validateCmd.Flags().BoolVar(&flagTable, "table", false, "Output results as a table, one row per address (single and stdin modes)")
+	validateCmd.Flags().StringSliceVar(&flagColumns, "columns", nil, "Result fields to show as --table columns, in order (default email,state,sub_state,domain,suggestion,source)")
 	validateCmd.Flags().BoolVarP(&flagQuiet, "quiet", "q", false, "Output only the state (ok/email_invalid/accept_all)")
+	validateCmd.Flags().IntVar(&flagConcurrency, "concurrency", 4, "Number of requests to run in parallel (file and stdin modes)")
+	validateCmd.Flags().IntVar(&flagBatchSize, "batch-size", 20, fmt.Sprintf("Emails to send per API request, up to %d (file and stdin modes)", client.MaxBatchSize))
+	validateCmd.Flags().BoolVar(&flagNoCache, "no-cache", false, "Don't read from or write to the local result cache")
+	validateCmd.Flags().BoolVar(&flagRefresh, "refresh", false, "Ignore cached results but store the new ones")
+	validateCmd.Flags().StringVar(&flagPrecheck, "precheck", precheckSyntax, "Addresses to settle locally without the API: syntax (invalid syntax) or local (also disposable domains and role accounts)")
+	validateCmd.Flags().BoolVar(&flagCheckMX, "check-mx", false, "Look up the MX records of each domain once and settle addresses on domains that can't receive email locally")
+	validateCmd.Flags().BoolVar(&flagNoPrecheck, "no-precheck", false, "Send every address to the API, even ones that fail the local syntax check")
+	validateCmd.Flags().BoolVar(&flagFoldAliases, "fold-aliases", false, "Fold provider aliases into the mailbox they deliver to, e.g. j.doe+news@gmail.com into jdoe@gmail.com")
+	validateCmd.Flags().StringVar(&flagFixTypos, "fix-typos", "", "Look for misspelled provider domains such as gmial.con: annotate (add the correction) or rewrite (validate the corrected address) (file mode)")
+	validateCmd.Flags().BoolVar(&flagResume, "resume", false, "Continue an interrupted --file run from its checkpoint")
+	validateCmd.Flags().StringVar(&flagWhere, "where", "", `Only output results matching an expression, e.g. 'state == ok and sub_state != is_role'`)
+	validateCmd.Flags().StringVar(&flagRejects, "rejects", "", "Write results filtered out by --where to this file")
+	validateCmd.Flags().StringSliceVar(&flagFailOn, "fail-on", nil, "Exit non-zero if any result is in one of these states (email_invalid, accept_all, unknown)")
+	validateCmd.Flags().StringVar(&flagSplitBy, "split-by", "", "Write one CSV per state or sub_state instead of a single output file (file mode)")
+	validateCmd.Flags().StringSliceVar(&flagFields, "fields", nil, "Fields to add to CSV output, in order (default normalized,state,sub_state,domain,verified_at,suggestion,attempts,source)")
+	validateCmd.Flags().StringVar(&flagColPrefix, "column-prefix", defaultColumnPrefix, `Prefix for the names of result columns in CSV output; "" for none`)
+	validateCmd.Flags().BoolVar(&flagDedupeOut, "dedupe-output", false, "Leave rows repeating an earlier row's address out of the output (file mode)")
 
 	rootCmd.AddCommand(validateCmd)
 }
 
 var validateCmd = &cobra.Command{
-	Use:   "validate [email]",
+	Use:   "validate [email...]",
 	Short: "Validate one or more email addresses",
 	Long: `Validate email addresses using the Truelist API.
 
 Single email:
   truelist validate user@example.com
 
+Several emails:
+  truelist validate user@example.com other@example.com
+
 CSV file:
   truelist validate --file emails.csv
 
 Stdin (pipe):
   cat emails.txt | truelist validate`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		apiKey, err := config.GetAPIKey()
+		if flagConcurrency < 1 {
+			err := usageErrorf("--concurrency must be at least 1")
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		if flagBatchSize < 1 || flagBatchSize > client.MaxBatchSize {
+			err := usageErrorf("--batch-size must be between 1 and %d", client.MaxBatchSize)
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		if flagJSON && flagJSONL {
+			err := usageErrorf("--json and --jsonl cannot be used together")
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		if err := checkFailOn(flagFailOn); err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		where, err := parseWhere()
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		if flagFormat != "" && (flagJSON || flagJSONL || flagQuiet || flagTable) {
+			err := usageErrorf("--format cannot be used with --json, --jsonl, --quiet or --table")
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+		tmpl, err := parseFormat(flagFormat, &client.ValidationResult{})
 		if err != nil {
-			output.PrintError(os.Stderr, err.Error())
+			output.PrintError(os.Stderr, err)
 			return err
 		}
 
-		c := client.New(apiKey)
+		var table *output.ResultTable
+		if flagTable {
+			if flagJSON || flagJSONL || flagQuiet {
+				err := usageErrorf("--table cannot be used with --json, --jsonl or --quiet")
+				output.PrintError(os.Stderr, err)
+				return err
+			}
+			columns, err := checkFields("--columns", flagColumns, client.ResultFields, output.DefaultTableFields)
+			if err != nil {
+				output.PrintError(os.Stderr, err)
+				return err
+			}
+			table = output.NewResultTable(os.Stdout, columns, output.TerminalWidth(os.Stdout))
+		} else if flagColumns != nil {
+			err := usageErrorf("--columns needs --table")
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		c, err := newClient()
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		bv := &bulkValidator{
+			client:    c,
+			norm:      normalize.Options{FoldAliases: flagFoldAliases},
+			refresh:   flagRefresh,
+			workers:   flagConcurrency,
+			batchSize: flagBatchSize,
+		}
+		if !flagNoPrecheck {
+			bv.precheck, err = newPrechecker(flagPrecheck)
+			if err != nil {
+				output.PrintError(os.Stderr, err)
+				return err
+			}
+			if flagCheckMX && !bv.precheck.enableMX(cmd.Context(), net.DefaultResolver) {
+				fmt.Fprintf(os.Stderr, "Warning: DNS lookups are not working (%s has no mail servers); continuing without --check-mx\n", mxCanary)
+			}
+		} else if cmd.Flags().Changed("precheck") || flagCheckMX {
+			err := usageErrorf("--precheck and --check-mx cannot be used with --no-precheck")
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+		if flagFixTypos != "" {
+			if flagFile == "" {
+				err := usageErrorf("--fix-typos only applies to --file")
+				output.PrintError(os.Stderr, err)
+				return err
+			}
+			bv.typos, err = newTypoFixer(flagFixTypos)
+			if err != nil {
+				output.PrintError(os.Stderr, err)
+				return err
+			}
+		}
+		if !flagNoCache {
+			rc, err := openCache()
+			if err != nil {
+				output.PrintError(os.Stderr, err)
+				return err
+			}
+			defer rc.Close()
+			bv.cache = rc
+		}
 
 		// Determine mode: file, stdin, or single email.
+		ctx := cmd.Context()
 		switch {
 		case flagFile != "":
-			return runFileValidation(c)
+			return runFileValidation(ctx, bv, where, tmpl)
 		case len(args) == 0:
-			return runStdinValidation(c)
+			return runStdinValidation(ctx, bv, where, tmpl, table)
+		case len(args) == 1:
+			return runSingleValidation(ctx, bv, where, tmpl, table, bv.address(args[0]))
 		default:
-			return runSingleValidation(c, args[0])
+			return runArgsValidation(ctx, bv, where, tmpl, table, args)
 		}
 	},
 }
 
-func runSingleValidation(c *client.Client, email string) error {
-	result, err := c.Validate(context.Background(), email)
+func runSingleValidation(ctx context.Context, bv *bulkValidator, where *filter.Expr, tmpl *template.Template, table *output.ResultTable, email string) error {
+	rejects, err := openRejectLines()
 	if err != nil {
-		output.PrintError(os.Stderr, err.Error())
+		output.PrintError(os.Stderr, err)
+		return err
+	}
+	defer rejects.close()
+
+	v := bv.validateOne(ctx, email)
+	if v.err != nil {
+		err := v.err
+		if ctx.Err() != nil {
+			err = context.Cause(ctx)
+		}
+		output.PrintError(os.Stderr, err)
 		return err
 	}
+	result := v.result
 
 	switch {
+	case where != nil && !where.Match(result):
+		err = rejects.add(email)
+	case tmpl != nil:
+		err = output.PrintTemplate(os.Stdout, tmpl, result)
+	case table != nil:
+		err = table.Add(result)
 	case flagJSON:
-		return output.PrintValidationJSON(os.Stdout, result)
+		err = output.PrintValidationJSON(os.Stdout, result)
+	case flagJSONL:
+		err = output.PrintValidationJSONL(os.Stdout, result)
 	case flagQuiet:
 		output.PrintValidationQuiet(os.Stdout, result)
 	default:
 		output.PrintValidationResult(os.Stdout, result)
 	}
-	return nil
+	if err != nil {
+		return err
+	}
+
+	var counts tally
+	counts.add(result.State)
+	return failOnOutcome(&counts, flagFailOn)
 }
 
-func runStdinValidation(c *client.Client) error {
+func runStdinValidation(ctx context.Context, bv *bulkValidator, where *filter.Expr, tmpl *template.Template, table *output.ResultTable) error {
 	// Check if stdin is a pipe.
 	stat, _ := os.Stdin.Stat()
 	if (stat.Mode() & os.ModeCharDevice) != 0 {
-		return fmt.Errorf("no email provided — pass an email as an argument, use --file, or pipe from stdin")
+		err := usageErrorf("no email provided — pass an email as an argument, use --file, or pipe from stdin")
+		output.PrintError(os.Stderr, err)
+		return err
 	}
 
 	scanner := bufio.NewScanner(os.Stdin)
-	var results []*client.ValidationResult
-	counts := map[string]int{"ok": 0, "email_invalid": 0, "accept_all": 0, "unknown": 0}
+	return runListValidation(ctx, bv, where, tmpl, table, func(submit submitFunc) error {
+		for scanner.Scan() {
+			email := bv.address(scanner.Text())
+			if email == "" {
+				continue
+			}
+			if !submit(nil, email) {
+				break
+			}
+		}
+		return scanner.Err()
+	})
+}
 
-	for scanner.Scan() {
-		email := strings.TrimSpace(scanner.Text())
-		if email == "" {
-			continue
+// runArgsValidation validates several addresses given as arguments, the
+// same way as a list piped to stdin.
+func runArgsValidation(ctx context.Context, bv *bulkValidator, where *filter.Expr, tmpl *template.Template, table *output.ResultTable, emails []string) error {
+	return runListValidation(ctx, bv, where, tmpl, table, func(submit submitFunc) error {
+		for _, email := range emails {
+			email = bv.address(email)
+			if email == "" {
+				continue
+			}
+			if !submit(nil, email) {
+				break
+			}
 		}
+		return nil
+	})
+}
 
-		result, err := c.Validate(context.Background(), email)
-		if err != nil {
-			output.PrintError(os.Stderr, fmt.Sprintf("failed to validate %s: %s", email, err))
-			continue
+// runListValidation validates a list of addresses from produce, printing
+// each result in order and a summary at the end.
+func runListValidation(ctx context.Context, bv *bulkValidator, where *filter.Expr, tmpl *template.Template, table *output.ResultTable, produce func(submit submitFunc) error) error {
+	rejects, err := openRejectLines()
+	if err != nil {
+		output.PrintError(os.Stderr, err)
+		return err
+	}
+	defer rejects.close()
+
+	var results []*client.ValidationResult
+	var counts tally
+
+	consume := func(q queued, v *validation) error {
+		if v.err != nil {
+			err := fmt.Errorf("failed to validate %s: %w", q.email, v.err)
+			// In JSONL and table output, failures are shown in order, like
+			// results.
+			var werr error
+			switch {
+			case flagJSONL:
+				werr = output.PrintErrorJSONL(os.Stdout, q.email, v.err)
+			case table != nil:
+				werr = table.AddError(q.email, v.err)
+			}
+			if werr != nil {
+				return werr
+			}
+			if isFatal(v.err) {
+				return err
+			}
+			counts.addFailure(v.err)
+			if !flagJSONL && table == nil {
+				output.PrintError(os.Stderr, err)
+			}
+			return nil
 		}
 
-		results = append(results, result)
-		state := strings.ToLower(result.State)
-		if _, exists := counts[state]; exists {
-			counts[state]++
-		} else {
-			counts["unknown"]++
+		result := v.result
+		counts.addValidation(v)
+		if where != nil && !where.Match(result) {
+			counts.rejected++
+			return rejects.add(q.email)
 		}
 
-		if flagJSON {
+		switch {
+		case tmpl != nil:
+			return output.PrintTemplate(os.Stdout, tmpl, result)
+		case table != nil:
+			return table.Add(result)
+		case flagJSON:
 			// In JSON mode, we'll collect and print at the end.
-			continue
+			results = append(results, result)
+			return nil
+		case flagJSONL:
+			return output.PrintValidationJSONL(os.Stdout, result)
 		}
 		if flagQuiet {
 			output.PrintValidationQuiet(os.Stdout, result)
@@ -129,6 +370,19 @@ func runStdinValidation(c *client.Client) error {
 			output.PrintValidationResult(os.Stdout, result)
 			fmt.Println()
 		}
+		return nil
+	}
+
+	scanErr := bv.run(ctx, produce, consume)
+	counts.deadDomains = bv.precheck.deadDomains()
+	if scanErr != nil {
+		output.PrintError(os.Stderr, scanErr)
+	}
+
+	if table != nil {
+		if err := table.Flush(); err != nil {
+			return err
+		}
 	}
 
 	if flagJSON {
@@ -139,25 +393,77 @@ func runStdinValidation(c *client.Client) error {
 		}
 	}
 
-	if !flagQuiet && !flagJSON {
-		total := counts["ok"] + counts["email_invalid"] + counts["accept_all"] + counts["unknown"]
-		output.PrintSummary(os.Stdout, total, counts["ok"], counts["email_invalid"], counts["accept_all"], counts["unknown"])
+	if !flagQuiet && !flagJSON && !flagJSONL && tmpl == nil {
+		counts.print(os.Stdout)
 	}
 
-	return scanner.Err()
+	if scanErr != nil {
+		return scanErr
+	}
+	return runOutcome(&counts, flagFailOn)
 }
 
-func runFileValidation(c *client.Client) error {
-	if flagJSON {
-		return fmt.Errorf("--json flag is not supported with --file mode (CSV output is always used)")
-	}
+func runFileValidation(ctx context.Context, bv *bulkValidator, where *filter.Expr, tmpl *template.Template) error {
 	if flagQuiet {
-		return fmt.Errorf("--quiet flag is not supported with --file mode (CSV output is always used)")
+		err := usageErrorf("--quiet flag is not supported with --file mode (use --json or --jsonl for machine-readable output)")
+		output.PrintError(os.Stderr, err)
+		return err
+	}
+
+	if flagTable {
+		err := usageErrorf("--table is not supported with --file mode (use --format for custom text output)")
+		output.PrintError(os.Stderr, err)
+		return err
+	}
+
+	format := formatCSV
+	switch {
+	case flagJSON:
+		format = formatJSON
+	case flagJSONL:
+		format = formatJSONL
+	case tmpl != nil:
+		format = formatText
+	}
+
+	if format != formatCSV && (flagFields != nil || flagColPrefix != defaultColumnPrefix) {
+		err := usageErrorf("--fields and --column-prefix only apply to CSV output")
+		output.PrintError(os.Stderr, err)
+		return err
+	}
+	fields, err := checkFields("--fields", flagFields, csvFields, defaultFields)
+	if err != nil {
+		output.PrintError(os.Stderr, err)
+		return err
+	}
+	// --fix-typos always records what it found.
+	switch flagFixTypos {
+	case fixTyposAnnotate:
+		fields = withField(fields, "correction")
+	case fixTyposRewrite:
+		fields = withField(fields, "original")
+	}
+
+	if flagSplitBy != "" {
+		var err error
+		switch {
+		case flagSplitBy != "state" && flagSplitBy != "sub_state":
+			err = usageErrorf("--split-by must be state or sub_state")
+		case format != formatCSV:
+			err = usageErrorf("--split-by writes CSV files and cannot be used with --json, --jsonl or --format")
+		case flagOutput == "-":
+			err = usageErrorf("--split-by writes several files and cannot write to stdout")
+		}
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
 	}
 
 	f, err := os.Open(flagFile)
 	if err != nil {
-		output.PrintError(os.Stderr, fmt.Sprintf("could not open file: %s", err))
+		err = fmt.Errorf("could not open file: %w", err)
+		output.PrintError(os.Stderr, err)
 		return err
 	}
 	defer f.Close()
@@ -167,128 +473,341 @@ func runFileValidation(c *client.Client) error {
 	// Read header row.
 	header, err := reader.Read()
 	if err != nil {
-		output.PrintError(os.Stderr, fmt.Sprintf("could not read CSV header: %s", err))
+		err = fmt.Errorf("could not read CSV header: %w", err)
+		output.PrintError(os.Stderr, err)
 		return err
 	}
 
-	emailColIdx := findEmailColumn(header, flagColumn)
-	if emailColIdx == -1 {
-		errMsg := "could not detect email column — use --column to specify it"
-		if flagColumn != "" {
-			errMsg = fmt.Sprintf("column %q not found in CSV header", flagColumn)
-		}
-		output.PrintError(os.Stderr, errMsg)
-		return fmt.Errorf(errMsg)
+	emailColIdx, err := emailColumn(header, flagColumn)
+	if err != nil {
+		output.PrintError(os.Stderr, err)
+		return err
+	}
+	layout, err := newCSVLayout(header, fields, flagColPrefix)
+	if err != nil {
+		output.PrintError(os.Stderr, err)
+		return err
 	}
 
-	// Read all rows so we can show a progress bar.
-	var rows [][]string
-	for {
-		row, readErr := reader.Read()
-		if readErr == io.EOF {
-			break
+	// Determine output path. With --split-by, it is what the split files
+	// are named after instead: contacts.csv becomes contacts_ok.csv and so
+	// on, and the checkpoint goes next to them as contacts.checkpoint.
+	outPath := flagOutput
+	cpOutput := outPath
+	switch {
+	case flagSplitBy != "":
+		if outPath == "" {
+			outPath = flagFile
+		}
+		cpOutput = strings.TrimSuffix(outPath, filepath.Ext(outPath))
+	case outPath == "":
+		outPath = defaultOutputPath(flagFile)
+		if format != formatCSV {
+			outPath = strings.TrimSuffix(outPath, filepath.Ext(outPath)) + formatExt(format)
+		}
+		cpOutput = outPath
+	}
+	toStdout := outPath == "-"
+
+	counts := tally{typosFixed: bv.typos != nil && bv.typos.rewrite}
+
+	// Each unique address is validated once and its result reused for
+	// every row that repeats it.
+	bv.dedupe = newDeduper(flagDedupeOut)
+	defer bv.dedupe.close()
+
+	// Checkpoints need an input that can be hashed and re-read, and output
+	// files that can be counted and appended to. Every row must also end up
+	// in some file: rows dropped by --where without --rejects leave nothing
+	// to count on resume.
+	var cpt *checkpointer
+	if flagResume {
+		var err error
+		switch {
+		case !isRegularFile(flagFile):
+			err = usageErrorf("--resume needs a regular input file, not a pipe")
+		case toStdout:
+			err = usageErrorf("--resume cannot continue output written to stdout")
+		case format == formatJSON:
+			err = usageErrorf("--resume cannot continue --json output; use --jsonl for runs you may need to resume")
+		case format == formatText:
+			err = usageErrorf("--resume cannot continue --format output")
+		case where != nil && flagRejects == "":
+			err = usageErrorf("--resume needs --rejects when --where is used")
 		}
-		if readErr != nil {
-			output.PrintError(os.Stderr, fmt.Sprintf("error reading CSV: %s", readErr))
-			return readErr
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
 		}
-		rows = append(rows, row)
 	}
+	resumable := !toStdout && format != formatJSON && format != formatText && (where == nil || flagRejects != "")
 
-	// Determine output path.
-	outPath := flagOutput
-	if outPath == "" {
-		ext := filepath.Ext(flagFile)
-		base := strings.TrimSuffix(flagFile, ext)
-		outPath = base + "_validated" + ext
+	if flagResume {
+		cp, err := checkpoint.Load(cpOutput)
+		if err == nil {
+			err = cp.Verify(flagFile)
+		}
+		if err == nil && cp.SplitBy != flagSplitBy {
+			err = usageErrorf("the interrupted run used --split-by %q — resume it with the same flag", cp.SplitBy)
+		}
+		if err == nil && cp.Format == "" {
+			cp.Format = formatCSV
+		}
+		if err == nil && cp.Format != format {
+			err = usageErrorf("the interrupted run wrote %s output — resume it with the same format", cp.Format)
+		}
+		if err == nil && cp.Rejects != flagRejects {
+			err = usageErrorf("the interrupted run used --rejects %q — resume it with the same flag", cp.Rejects)
+		}
+		if err == nil && cp.FixTypos != flagFixTypos {
+			err = usageErrorf("the interrupted run used --fix-typos %q — resume it with the same flag", cp.FixTypos)
+		}
+		switch {
+		case err != nil:
+		case cp.FoldAliases && !flagFoldAliases:
+			err = usageErrorf("the interrupted run used --fold-aliases — resume it with the same flag")
+		case !cp.FoldAliases && flagFoldAliases:
+			err = usageErrorf("the interrupted run did not use --fold-aliases — resume it without the flag")
+		}
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+		cpt = &checkpointer{cp: cp}
+	} else if isRegularFile(flagFile) && resumable {
+		cp, err := checkpoint.New(flagFile, cpOutput)
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+		cp.SplitBy = flagSplitBy
+		cp.Format = format
+		cp.Rejects = flagRejects
+		cp.FoldAliases = flagFoldAliases
+		cp.FixTypos = flagFixTypos
+		cpt = &checkpointer{cp: cp}
 	}
 
-	outFile, err := os.Create(outPath)
+	out, skipRows, err := openOutput(outPath, layout, tmpl, format, flagSplitBy, flagResume, cpt, &counts)
 	if err != nil {
-		output.PrintError(os.Stderr, fmt.Sprintf("could not create output file: %s", err))
+		output.PrintError(os.Stderr, err)
 		return err
 	}
-	defer outFile.Close()
-
-	writer := csv.NewWriter(outFile)
-
-	// Write header with new columns.
-	outHeader := append(header, "truelist_state", "truelist_sub_state", "truelist_domain", "truelist_verified_at", "truelist_suggestion")
-	if err := writer.Write(outHeader); err != nil {
-		return fmt.Errorf("failed to write header: %w", err)
+	// Closing ends a JSON array, so on success the outputs are closed
+	// below and their errors reported; these only run on early returns.
+	closed := false
+	defer func() {
+		if !closed {
+			out.close()
+		}
+	}()
+
+	// Rows filtered out by --where go to a file in the same format.
+	var rejects rowOutput
+	if flagRejects != "" {
+		var rows int
+		rejects, rows, err = openRowFile(flagRejects, layout, tmpl, format, flagResume, &counts)
+		skipRows += rows
+		counts.rejected += rows
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+		defer func() {
+			if !closed {
+				rejects.close()
+			}
+		}()
 	}
 
-	// Progress bar.
-	bar := progressbar.NewOptions(len(rows),
-		progressbar.OptionSetDescription("Validating"),
-		progressbar.OptionSetWriter(os.Stderr),
-		progressbar.OptionShowCount(),
-		progressbar.OptionSetWidth(40),
-		progressbar.OptionThrottle(100*1e6), // 100ms as nanoseconds
-		progressbar.OptionClearOnFinish(),
-		progressbar.OptionSetPredictTime(true),
-	)
+	if flagResume {
+		cpt.cp.RowsWritten = skipRows
+		fmt.Fprintf(os.Stderr, "Resuming after %d rows already in %s\n", skipRows, strings.Join(out.paths(), ", "))
+	}
+	if cpt != nil {
+		if err := cpt.cp.Save(); err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+	}
 
-	counts := map[string]int{"ok": 0, "email_invalid": 0, "accept_all": 0, "unknown": 0}
+	// Progress bar. Rows are streamed, so the total comes from a separate
+	// pass over the file, or from its size if it is too large to count.
+	progress, err := newCSVProgress(f, flagFile, reader.InputOffset())
+	if err != nil {
+		output.PrintError(os.Stderr, err)
+		return err
+	}
 
-	for _, row := range rows {
+	// With --dedupe-output, the rows already written don't include the
+	// repeats that were dropped, so those are skipped without counting.
+	read := 0
+	for written := 0; written < skipRows; read++ {
+		row, err := reader.Read()
+		if err != nil {
+			err = fmt.Errorf("could not skip already validated rows: %w", err)
+			output.PrintError(os.Stderr, err)
+			return err
+		}
 		if emailColIdx >= len(row) {
-			outRow := append(row, "", "", "", "", "")
-			_ = writer.Write(outRow)
-			_ = bar.Add(1)
+			written++
 			continue
 		}
+		q := queued{email: bv.address(row[emailColIdx])}
+		bv.typos.apply(&q)
+		repeat := bv.dedupe.skip(q.email)
+		counts.addRow(q.email, !repeat)
+		if !repeat || !flagDedupeOut {
+			written++
+		}
+	}
+	progress.skipped(read, reader.InputOffset())
 
-		email := strings.TrimSpace(row[emailColIdx])
-		if email == "" {
-			outRow := append(row, "", "", "", "", "")
-			_ = writer.Write(outRow)
-			_ = bar.Add(1)
-			continue
+	produce := func(submit submitFunc) error {
+		for {
+			row, readErr := reader.Read()
+			if readErr == io.EOF {
+				return nil
+			}
+			if readErr != nil {
+				return fmt.Errorf("error reading CSV: %w", readErr)
+			}
+			progress.read(reader.InputOffset())
+
+			email := ""
+			if emailColIdx < len(row) {
+				email = bv.address(row[emailColIdx])
+			}
+			if !submit(row, email) {
+				return nil
+			}
 		}
+	}
 
-		result, validateErr := c.Validate(context.Background(), email)
-		if validateErr != nil {
-			fmt.Fprintf(os.Stderr, "\nWarning: failed to validate %s: %s\n", email, validateErr)
-			outRow := append(row, "error", validateErr.Error(), "", "", "")
-			_ = writer.Write(outRow)
-			_ = bar.Add(1)
-			continue
+	consume := func(q queued, v *validation) error {
+		// Rows are counted here rather than as they are read, so an
+		// interrupted run counts only the rows it got to.
+		counts.addRow(q.email, q.first)
+		if q.repeat && flagDedupeOut {
+			progress.written()
+			return nil
 		}
 
-		state := strings.ToLower(result.State)
-		if _, exists := counts[state]; exists {
-			counts[state]++
-		} else {
-			counts["unknown"]++
+		if q.original != "" || q.correction != "" {
+			counts.typos++
 		}
 
-		suggestion := ""
-		if result.Suggestion != nil {
-			suggestion = *result.Suggestion
+		switch {
+		case v == nil:
+		case v.err != nil:
+			if isFatal(v.err) {
+				return fmt.Errorf("failed to validate %s: %w", q.email, v.err)
+			}
+			counts.addFailure(v.err)
+			if !q.repeat {
+				fmt.Fprintf(os.Stderr, "\nWarning: failed to validate %s: %s\n", q.email, v.err)
+			}
+		default:
+			counts.addValidation(v)
 		}
 
-		outRow := append(row, result.State, result.SubState, result.Domain, result.VerifiedAt, suggestion)
-		if writeErr := writer.Write(outRow); writeErr != nil {
-			return fmt.Errorf("failed to write row: %w", writeErr)
+		if where != nil && !where.Match(whereView(q.email, v)) {
+			counts.rejected++
+			if rejects != nil {
+				if err := rejects.write(q, v); err != nil {
+					return err
+				}
+			}
+		} else if err := out.write(q, v); err != nil {
+			return err
 		}
+		if err := cpt.wrote(); err != nil {
+			return err
+		}
+
+		progress.written()
+		return nil
+	}
+
+	runErr := bv.run(ctx, produce, consume)
+	progress.finish()
+	counts.deadDomains = bv.precheck.deadDomains()
+	if flagSplitBy != "" {
+		counts.files = out.paths()
+	}
+
+	if runErr != nil {
+		// Everything validated so far is already on disk; say how to pick
+		// up from there and show what was done.
+		if cpt != nil {
+			_ = cpt.cp.Save()
+			fmt.Fprintf(os.Stderr, "\nPartial results written to %s; rerun with --resume to continue\n", strings.Join(out.paths(), ", "))
+		}
+		output.PrintError(os.Stderr, runErr)
+		counts.print(os.Stderr)
+		return runErr
+	}
 
-		_ = bar.Add(1)
+	closed = true
+	err = out.close()
+	if rejects != nil {
+		err = errors.Join(err, rejects.close())
+	}
+	if err != nil {
+		output.PrintError(os.Stderr, err)
+		counts.print(os.Stderr)
+		return err
 	}
 
-	_ = bar.Finish()
+	if err := cpt.done(); err != nil {
+		output.PrintError(os.Stderr, err)
+	}
 
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("failed to write CSV output: %w", err)
+	if flagSplitBy == "" && !toStdout {
+		fmt.Fprintf(os.Stderr, "\nResults written to %s\n", outPath)
 	}
 
-	fmt.Fprintf(os.Stderr, "\nResults written to %s\n", outPath)
+	counts.print(os.Stderr)
 
-	total := counts["ok"] + counts["email_invalid"] + counts["accept_all"] + counts["unknown"]
-	output.PrintSummary(os.Stderr, total, counts["ok"], counts["email_invalid"], counts["accept_all"], counts["unknown"])
+	return runOutcome(&counts, flagFailOn)
+}
+
+// openOutput opens file mode's output: a single file in the given format,
+// or one CSV per state or sub-state when splitBy is set. When resuming, it
+// reopens the files an interrupted run left and returns the number of rows
+// they already hold.
+func openOutput(outPath string, layout *csvLayout, tmpl *template.Template, format, splitBy string, resume bool, cpt *checkpointer, counts *tally) (rowOutput, int, error) {
+	if splitBy == "" {
+		return openRowFile(outPath, layout, tmpl, format, resume, counts)
+	}
+
+	split := newSplitOutput(splitBy, outPath, layout)
+	if cpt != nil {
+		// Record new files right away, so a resume finds all of them.
+		split.created = func(part string) error {
+			cpt.cp.Parts = append(cpt.cp.Parts, part)
+			return cpt.cp.Save()
+		}
+	}
+
+	rows := 0
+	var err error
+	if resume {
+		rows, err = split.resume(cpt.cp.Parts, counts)
+	} else {
+		err = split.create()
+	}
+	if err != nil {
+		split.close()
+		return nil, 0, err
+	}
+	return split, rows, nil
+}
 
-	return nil
+// isFatal reports whether a per-email validation error will affect every
+// remaining email too, so a bulk run should stop rather than continue.
+func isFatal(err error) bool {
+	return errors.Is(err, client.ErrUnauthorized) || errors.Is(err, client.ErrQuotaExceeded)
 }
 
 // findEmailColumn locates the email column in the CSV header.
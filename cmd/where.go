@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/filter"
+)
+
+// parseWhere parses --where, returning nil when it is not set.
+func parseWhere() (*filter.Expr, error) {
+	if flagWhere == "" {
+		if flagRejects != "" {
+			return nil, usageErrorf("--rejects needs --where")
+		}
+		return nil, nil
+	}
+	expr, err := filter.Parse(flagWhere)
+	if err != nil {
+		return nil, usageErrorf("invalid --where expression %s", err)
+	}
+	return expr, nil
+}
+
+// whereView returns the result --where is evaluated against for a row. A
+// failed validation looks like a result with state "error", and a row
+// without an email like a result with every field empty.
+func whereView(email string, v *validation) *client.ValidationResult {
+	switch {
+	case v == nil:
+		return &client.ValidationResult{}
+	case v.err != nil:
+		return &client.ValidationResult{Email: email, State: "error"}
+	default:
+		return v.result
+	}
+}
+
+// rejectLines writes the addresses --where filters out to --rejects, one
+// per line, in stdin and single modes. A nil rejectLines discards them.
+type rejectLines struct {
+	f *os.File
+}
+
+// openRejectLines creates the --rejects file, or returns nil if it is not
+// set.
+func openRejectLines() (*rejectLines, error) {
+	if flagRejects == "" {
+		return nil, nil
+	}
+	f, err := os.Create(flagRejects)
+	if err != nil {
+		return nil, fmt.Errorf("could not create rejects file: %w", err)
+	}
+	return &rejectLines{f: f}, nil
+}
+
+func (r *rejectLines) add(email string) error {
+	if r == nil {
+		return nil
+	}
+	if _, err := fmt.Fprintln(r.f, email); err != nil {
+		return fmt.Errorf("failed to write rejects file: %w", err)
+	}
+	return nil
+}
+
+func (r *rejectLines) close() error {
+	if r == nil {
+		return nil
+	}
+	return r.f.Close()
+}
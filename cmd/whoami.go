@@ -1,16 +1,17 @@
 package cmd
 
 import (
-	"context"
 	"os"
 
 	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
-	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/config"
 	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
+var flagWhoamiFormat string
+
 func init() {
+	whoamiCmd.Flags().StringVar(&flagWhoamiFormat, "format", "", "Format the account info with a Go template, e.g. '{{.Email}} {{.Account.PaymentPlan}}'")
 	rootCmd.AddCommand(whoamiCmd)
 }
 
@@ -19,19 +20,27 @@ var whoamiCmd = &cobra.Command{
 	Short: "Display current account information",
 	Long:  "Check your API key and display account details including email, name, and plan.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		apiKey, err := config.GetAPIKey()
+		tmpl, err := parseFormat(flagWhoamiFormat, &client.AccountInfo{})
+		if err != nil {
+			output.PrintError(os.Stderr, err)
+			return err
+		}
+
+		c, err := newClient()
 		if err != nil {
-			output.PrintError(os.Stderr, err.Error())
+			output.PrintError(os.Stderr, err)
 			return err
 		}
 
-		c := client.New(apiKey)
-		info, err := c.Whoami(context.Background())
+		info, err := c.Whoami(cmd.Context())
 		if err != nil {
-			output.PrintError(os.Stderr, err.Error())
+			output.PrintError(os.Stderr, err)
 			return err
 		}
 
+		if tmpl != nil {
+			return output.PrintTemplate(os.Stdout, tmpl, info)
+		}
 		output.PrintAccountInfo(os.Stdout, info)
 		return nil
 	},
@@ -0,0 +1,284 @@
+// Package address checks the syntax of email addresses offline.
+//
+// It follows the addr-spec grammar of RFC 5322 as restricted for SMTP by
+// RFC 5321: a dot-atom or quoted-string local part, and a domain name or
+// an IPv4 or IPv6 address literal. UTF-8 is allowed in local parts and
+// domains (RFC 6531); internationalized domains are checked in their
+// punycode form. Comments and the obsolete forms of RFC 5322 are not
+// accepted, since mail servers don't accept them either.
+package address
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// Length limits, in octets, from RFC 5321 section 4.5.3.1. An address is
+// limited by the 256-octet path less its angle brackets (RFC 3696 errata
+// 1690).
+const (
+	MaxLength       = 254
+	MaxLocalLength  = 64
+	MaxDomainLength = 253
+	MaxLabelLength  = 63
+)
+
+// Address is a syntactically valid address, split into its parts.
+type Address struct {
+	// Local is the local part as written, including any quotes.
+	Local string
+	// Domain is the domain as written; an address literal keeps its
+	// brackets.
+	Domain string
+	// ASCIIDomain is Domain in lowercase, with internationalized labels
+	// converted to punycode. It is Domain unchanged for address literals.
+	ASCIIDomain string
+	// Literal reports whether Domain is an IP address literal.
+	Literal bool
+}
+
+// SyntaxError explains why an address is not valid.
+type SyntaxError struct {
+	Address string
+	Reason  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid address %q: %s", e.Address, e.Reason)
+}
+
+// Parse checks the syntax of an address. It returns a *SyntaxError if the
+// address is invalid.
+func Parse(s string) (*Address, error) {
+	fail := func(format string, a ...any) (*Address, error) {
+		return nil, &SyntaxError{Address: s, Reason: fmt.Sprintf(format, a...)}
+	}
+
+	if s == "" {
+		return fail("empty address")
+	}
+	if !utf8.ValidString(s) {
+		return fail("not valid UTF-8")
+	}
+
+	var local, domain string
+	if strings.HasPrefix(s, `"`) {
+		end, reason := quotedEnd(s)
+		if reason != "" {
+			return fail("%s", reason)
+		}
+		local = s[:end]
+		rest := s[end:]
+		if !strings.HasPrefix(rest, "@") {
+			if rest == "" {
+				return fail("missing @")
+			}
+			return fail("unexpected %q after quoted local part", firstRune(rest))
+		}
+		domain = rest[1:]
+	} else {
+		at := strings.LastIndexByte(s, '@')
+		if at < 0 {
+			return fail("missing @")
+		}
+		local, domain = s[:at], s[at+1:]
+		if reason := checkDotAtom(local); reason != "" {
+			return fail("%s", reason)
+		}
+	}
+	if len(local) > MaxLocalLength {
+		return fail("local part is longer than %d characters", MaxLocalLength)
+	}
+
+	a := &Address{Local: local, Domain: domain}
+	switch {
+	case domain == "":
+		return fail("missing domain")
+	case strings.HasPrefix(domain, "["):
+		if reason := checkLiteral(domain); reason != "" {
+			return fail("%s", reason)
+		}
+		a.ASCIIDomain = domain
+		a.Literal = true
+	default:
+		ascii, reason := checkDomain(domain)
+		if reason != "" {
+			return fail("%s", reason)
+		}
+		a.ASCIIDomain = ascii
+	}
+
+	if len(local)+1+len(a.ASCIIDomain) > MaxLength {
+		return fail("address is longer than %d characters", MaxLength)
+	}
+	return a, nil
+}
+
+// isAtext reports whether c may appear in an unquoted local part, besides
+// dots separating atoms (RFC 5322 section 3.2.3). Bytes of multi-byte
+// UTF-8 characters are allowed by RFC 6531.
+func isAtext(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		strings.IndexByte("!#$%&'*+-/=?^_`{|}~", c) >= 0 || c >= 0x80
+}
+
+// checkDotAtom checks an unquoted local part.
+func checkDotAtom(local string) string {
+	switch {
+	case local == "":
+		return "missing local part before @"
+	case local[0] == '.':
+		return "local part starts with a dot"
+	case local[len(local)-1] == '.':
+		return "local part ends with a dot"
+	case strings.Contains(local, ".."):
+		return "local part has two dots in a row"
+	}
+	for i := 0; i < len(local); i++ {
+		if c := local[i]; c != '.' && !isAtext(c) {
+			return describeChar(firstRune(local[i:])) + " in local part (it must be quoted)"
+		}
+	}
+	return ""
+}
+
+// quotedEnd returns the index just past the closing quote of the quoted
+// local part at the start of s (RFC 5321 Quoted-string).
+func quotedEnd(s string) (int, string) {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			if i == 1 {
+				return 0, "empty quoted local part"
+			}
+			return i + 1, ""
+		case c == '\\':
+			i++
+			if i == len(s) || s[i] < 32 || s[i] > 126 {
+				return 0, "invalid escape in quoted local part"
+			}
+		case c < 32 || c == 127:
+			return 0, describeChar(rune(c)) + " in quoted local part"
+		}
+	}
+	return 0, "unterminated quoted local part"
+}
+
+// checkLiteral checks an address literal such as [192.0.2.1] or
+// [IPv6:2001:db8::1] (RFC 5321 section 4.1.3).
+func checkLiteral(domain string) string {
+	inner, ok := strings.CutSuffix(domain[1:], "]")
+	if !ok {
+		return "unterminated address literal"
+	}
+	if v6, ok := cutPrefixFold(inner, "IPv6:"); ok {
+		if ip, err := netip.ParseAddr(v6); err != nil || !ip.Is6() || ip.Zone() != "" {
+			return fmt.Sprintf("invalid IPv6 address literal %s", domain)
+		}
+		return ""
+	}
+	if ip, err := netip.ParseAddr(inner); err != nil || !ip.Is4() {
+		return fmt.Sprintf("invalid IP address literal %s", domain)
+	}
+	return ""
+}
+
+// checkDomain checks a domain name and returns its ASCII form.
+func checkDomain(domain string) (string, string) {
+	switch {
+	case domain[0] == '.':
+		return "", "domain starts with a dot"
+	case domain[len(domain)-1] == '.':
+		return "", "domain ends with a dot"
+	case strings.Contains(domain, ".."):
+		return "", "domain has two dots in a row"
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "", fmt.Sprintf("domain %q has no top-level domain", domain)
+	}
+	for i, label := range labels {
+		ascii := label
+		if !isASCII(label) {
+			var err error
+			ascii, err = idna.Lookup.ToASCII(label)
+			// A label of only ignored characters, such as a soft
+			// hyphen, maps to nothing.
+			if err != nil || ascii == "" {
+				return "", fmt.Sprintf("invalid internationalized domain label %q", label)
+			}
+		}
+		if reason := checkLabel(ascii); reason != "" {
+			return "", reason
+		}
+		labels[i] = strings.ToLower(ascii)
+	}
+	ascii := strings.Join(labels, ".")
+	if len(ascii) > MaxDomainLength {
+		return "", fmt.Sprintf("domain is longer than %d characters", MaxDomainLength)
+	}
+
+	tld := labels[len(labels)-1]
+	if strings.Trim(tld, "0123456789") == "" {
+		return "", "top-level domain is numeric (IP addresses must be in brackets, like [192.0.2.1])"
+	}
+	return ascii, ""
+}
+
+// checkLabel checks an ASCII domain label against the letters, digits and
+// hyphens rule of RFC 1035 as relaxed by RFC 1123.
+func checkLabel(label string) string {
+	if len(label) > MaxLabelLength {
+		return fmt.Sprintf("domain label %q is longer than %d characters", label, MaxLabelLength)
+	}
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return fmt.Sprintf("domain label %q starts or ends with a hyphen", label)
+	}
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+			return describeChar(rune(c)) + " in domain"
+		}
+	}
+	return ""
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+// describeChar names a character for an error message.
+func describeChar(r rune) string {
+	switch r {
+	case ' ':
+		return "space"
+	case '\t':
+		return "tab"
+	case '@':
+		return "extra @"
+	default:
+		return fmt.Sprintf("%q", r)
+	}
+}
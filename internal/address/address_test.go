@@ -0,0 +1,131 @@
+package address
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseValid(t *testing.T) {
+	tests := []struct {
+		in          string
+		local       string
+		asciiDomain string
+		literal     bool
+	}{
+		{"jo@example.com", "jo", "example.com", false},
+		{"Jo.Smith+tag@Example.COM", "Jo.Smith+tag", "example.com", false},
+		{"!#$%&'*+-/=?^_`{|}~@example.com", "!#$%&'*+-/=?^_`{|}~", "example.com", false},
+		{`"jo smith"@example.com`, `"jo smith"`, "example.com", false},
+		{`"jo\"s"@example.com`, `"jo\"s"`, "example.com", false},
+		{`"a@b"@example.com`, `"a@b"`, "example.com", false},
+		{"user@[192.0.2.1]", "user", "[192.0.2.1]", true},
+		{"user@[IPv6:2001:db8::1]", "user", "[IPv6:2001:db8::1]", true},
+		{"josé@bücher.de", "josé", "xn--bcher-kva.de", false},
+		{"jo@例え.jp", "jo", "xn--r8jz45g.jp", false},
+		{"jo@sub.example.co.uk", "jo", "sub.example.co.uk", false},
+		{"jo@123.example.com", "jo", "123.example.com", false},
+		{strings.Repeat("a", 64) + "@example.com", strings.Repeat("a", 64), "example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			a, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q) = %v", tt.in, err)
+			}
+			if a.Local != tt.local || a.ASCIIDomain != tt.asciiDomain || a.Literal != tt.literal {
+				t.Errorf("Parse(%q) = %+v, want local %q, ASCII domain %q, literal %v", tt.in, a, tt.local, tt.asciiDomain, tt.literal)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		in     string
+		reason string
+	}{
+		{"", "empty address"},
+		{"jo.example.com", "missing @"},
+		{"@example.com", "missing local part"},
+		{"jo@", "missing domain"},
+		{"jo smith@example.com", "space in local part"},
+		{"jo@@example.com", "extra @"},
+		{".jo@example.com", "local part starts with a dot"},
+		{"jo.@example.com", "local part ends with a dot"},
+		{"j..o@example.com", "two dots in a row"},
+		{`""@example.com`, "empty quoted local part"},
+		{`"jo@example.com`, "unterminated quoted local part"},
+		{`"jo"x@example.com`, "after quoted local part"},
+		{"jo@localhost", "no top-level domain"},
+		{"jo@.example.com", "domain starts with a dot"},
+		{"jo@example.com.", "domain ends with a dot"},
+		{"jo@example..com", "two dots in a row"},
+		{"jo@-example.com", "starts or ends with a hyphen"},
+		{"jo@ex_ample.com", `'_' in domain`},
+		{"jo@192.0.2.1", "top-level domain is numeric"},
+		{"jo@[192.0.2.1", "unterminated address literal"},
+		{"jo@[300.0.2.1]", "invalid IP address literal"},
+		{"jo@[IPv6:192.0.2.1]", "invalid IPv6 address literal"},
+		{"jo@\u00ad.example.com", "invalid internationalized domain label"},
+		{"jo@x.\u00ad", "invalid internationalized domain label"},
+		{strings.Repeat("a", 65) + "@example.com", "local part is longer than 64"},
+		{"jo@" + strings.Repeat("a", 64) + ".com", "longer than 63"},
+		{"jo@" + strings.Repeat("a.", 127) + "com", "domain is longer than 253"},
+		{"jo@ex\xffample.com", "not valid UTF-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			_, err := Parse(tt.in)
+			var serr *SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("Parse(%q) = %v, want a *SyntaxError", tt.in, err)
+			}
+			if !strings.Contains(serr.Reason, tt.reason) {
+				t.Errorf("Parse(%q) reason = %q, want it to contain %q", tt.in, serr.Reason, tt.reason)
+			}
+		})
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, s := range []string{
+		"jo@example.com",
+		`"jo smith"@example.com`,
+		"user@[IPv6:2001:db8::1]",
+		"josé@bücher.de",
+		"a@\u00ad.x_y.com",
+		"a@xn--.com",
+		"@",
+		`"\`,
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		a, err := Parse(s)
+		if err != nil {
+			var serr *SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("Parse(%q) returned %T, want *SyntaxError", s, err)
+			}
+			return
+		}
+		if a.Local == "" || a.ASCIIDomain == "" {
+			t.Fatalf("Parse(%q) = %+v, with an empty part", s, a)
+		}
+		if len(a.Local) > MaxLocalLength || len(a.Local)+1+len(a.ASCIIDomain) > MaxLength {
+			t.Fatalf("Parse(%q) = %+v, over the length limits", s, a)
+		}
+		if !utf8.ValidString(a.ASCIIDomain) {
+			t.Fatalf("Parse(%q) ASCII domain %q is not valid UTF-8", s, a.ASCIIDomain)
+		}
+		if !a.Literal {
+			for _, label := range strings.Split(a.ASCIIDomain, ".") {
+				if label == "" || len(label) > MaxLabelLength {
+					t.Fatalf("Parse(%q) ASCII domain %q has a bad label %q", s, a.ASCIIDomain, label)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,286 @@
+// Package cache stores validation results on disk so addresses checked
+// recently are not paid for again.
+//
+// The cache is an append-only JSON Lines file: each Put appends one entry,
+// and the newest entry for a key wins when the file is loaded. Prune
+// rewrites the file without expired or superseded entries.
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/config"
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/normalize"
+)
+
+const day = 24 * time.Hour
+
+// TTLs maps an email state to how long results in that state stay fresh.
+// States without an entry use the "unknown" TTL.
+type TTLs map[string]time.Duration
+
+// DefaultTTLs favors keeping definitive verdicts longer than ones that are
+// likely to change.
+var DefaultTTLs = TTLs{
+	"ok":            30 * day,
+	"email_invalid": 90 * day,
+	"accept_all":    7 * day,
+	"unknown":       1 * day,
+}
+
+// For returns the TTL for a state.
+func (t TTLs) For(state string) time.Duration {
+	if d, ok := t[strings.ToLower(state)]; ok {
+		return d
+	}
+	return t["unknown"]
+}
+
+// ParseTTL parses a duration, additionally accepting a whole number of
+// days such as "30d".
+func ParseTTL(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid TTL %q", s)
+		}
+		return time.Duration(n) * day, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid TTL %q", s)
+	}
+	return d, nil
+}
+
+// Entry is one cached result.
+type Entry struct {
+	Key        string                  `json:"key"`
+	Result     client.ValidationResult `json:"result"`
+	VerifiedAt time.Time               `json:"verified_at"`
+}
+
+// Cache is an on-disk result cache. It is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	path    string
+	ttl     TTLs
+	entries map[string]Entry
+	log     *os.File
+	now     func() time.Time
+}
+
+// DefaultPath returns the cache file path under the config directory.
+func DefaultPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cache", "results.jsonl"), nil
+}
+
+// Open loads the cache at path, creating it if needed.
+func Open(path string, ttl TTLs) (*Cache, error) {
+	c := &Cache{
+		path:    path,
+		ttl:     ttl,
+		entries: make(map[string]Entry),
+		now:     time.Now,
+	}
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("could not create cache directory: %w", err)
+	}
+	log, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open cache: %w", err)
+	}
+	c.log = log
+
+	return c, nil
+}
+
+// load reads every entry in the cache file. Lines that don't decode, such
+// as one torn by a crash mid-write, are skipped. A last line without a
+// newline is cut off the file, so the next Put starts a line of its own
+// instead of being glued onto the torn one.
+func (c *Cache) load() error {
+	f, err := os.Open(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not open cache: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var complete int64 // length of the file up to its last newline
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) == 0 {
+				return nil
+			}
+			if err := os.Truncate(c.path, complete); err != nil {
+				return fmt.Errorf("could not repair cache: %w", err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read cache: %w", err)
+		}
+		complete += int64(len(line))
+
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil || e.Key == "" {
+			continue
+		}
+		c.entries[e.Key] = e
+	}
+}
+
+// Get returns the cached result for email if there is a fresh one.
+func (c *Cache) Get(email string) (*client.ValidationResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[normalize.Key(email)]
+	if !ok || c.expired(e) {
+		return nil, false
+	}
+	r := e.Result
+	r.Attempts = 0
+	return &r, true
+}
+
+// Put stores a result for email, replacing any earlier entry.
+func (c *Cache) Put(email string, r *client.ValidationResult) error {
+	e := Entry{Key: normalize.Key(email), Result: *r, VerifiedAt: c.now().UTC()}
+	e.Result.Attempts = 0
+	if t, err := time.Parse(time.RFC3339, r.VerifiedAt); err == nil {
+		e.VerifiedAt = t.UTC()
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("could not encode cache entry: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[e.Key] = e
+	if _, err := c.log.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("could not write cache: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) expired(e Entry) bool {
+	return c.now().Sub(e.VerifiedAt) > c.ttl.For(e.Result.State)
+}
+
+// Stats describes the contents of the cache.
+type Stats struct {
+	Path    string
+	Size    int64
+	Entries int
+	Expired int
+	ByState map[string]int
+}
+
+// Stats returns counts of fresh entries by state and of expired entries.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := Stats{Path: c.path, Entries: len(c.entries), ByState: make(map[string]int)}
+	if fi, err := os.Stat(c.path); err == nil {
+		s.Size = fi.Size()
+	}
+	for _, e := range c.entries {
+		if c.expired(e) {
+			s.Expired++
+			continue
+		}
+		s.ByState[strings.ToLower(e.Result.State)]++
+	}
+	return s
+}
+
+// Prune rewrites the cache file without expired or superseded entries and
+// returns the number of entries removed.
+func (c *Cache) Prune() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), "results-*.jsonl")
+	if err != nil {
+		return 0, fmt.Errorf("could not prune cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	removed := 0
+	for key, e := range c.entries {
+		if c.expired(e) {
+			delete(c.entries, key)
+			removed++
+			continue
+		}
+		if err := enc.Encode(e); err != nil {
+			tmp.Close()
+			return 0, fmt.Errorf("could not prune cache: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("could not prune cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("could not prune cache: %w", err)
+	}
+
+	c.log.Close()
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return 0, fmt.Errorf("could not prune cache: %w", err)
+	}
+	c.log, err = os.OpenFile(c.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("could not open cache: %w", err)
+	}
+	return removed, nil
+}
+
+// Close releases the cache file.
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.log.Close()
+}
+
+// Clear deletes the cache file at path.
+func Clear(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not clear cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+)
+
+func entryLine(t *testing.T, email, state string) string {
+	t.Helper()
+	data, err := json.Marshal(Entry{
+		Key:        email,
+		Result:     client.ValidationResult{Email: email, State: state},
+		VerifiedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data) + "\n"
+}
+
+func TestOpenAfterTornWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	good := entryLine(t, "a@example.com", "ok")
+	torn := entryLine(t, "b@example.com", "ok")
+	torn = torn[:len(torn)/2]
+	if err := os.WriteFile(path, []byte(good+torn), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Open(path, DefaultTTLs)
+	if err != nil {
+		t.Fatalf("Open = %v", err)
+	}
+	if err := c.Put("c@example.com", &client.ValidationResult{Email: "c@example.com", State: "email_invalid"}); err != nil {
+		t.Fatalf("Put = %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = Open(path, DefaultTTLs)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer c.Close()
+	for _, email := range []string{"a@example.com", "c@example.com"} {
+		if _, ok := c.Get(email); !ok {
+			t.Errorf("Get(%s) found nothing after reopening", email)
+		}
+	}
+	if _, ok := c.Get("b@example.com"); ok {
+		t.Error("Get(b@example.com) found the torn entry")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"); len(lines) != 2 {
+		t.Errorf("cache file has %d lines, want the 2 good entries:\n%s", len(lines), data)
+	}
+}
+
+func TestOpenSkipsBadLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	data := entryLine(t, "a@example.com", "ok") +
+		"{\"key\":\"torn@example.com\",\"res" + entryLine(t, "glued@example.com", "ok") +
+		"\n" +
+		"not json\n" +
+		entryLine(t, "b@example.com", "ok")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Open(path, DefaultTTLs)
+	if err != nil {
+		t.Fatalf("Open = %v", err)
+	}
+	defer c.Close()
+	if _, ok := c.Get("a@example.com"); !ok {
+		t.Error("lost the entry before the bad lines")
+	}
+	if _, ok := c.Get("b@example.com"); !ok {
+		t.Error("lost the entry after the bad lines")
+	}
+	if n := c.Stats().Entries; n != 2 {
+		t.Errorf("loaded %d entries, want 2", n)
+	}
+}
+
+func TestGetExpired(t *testing.T) {
+	c, err := Open(filepath.Join(t.TempDir(), "results.jsonl"), TTLs{"ok": time.Hour, "unknown": time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	if err := c.Put("Jo@Example.com", &client.ValidationResult{State: "ok", Attempts: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put("x@example.com", &client.ValidationResult{State: "unknown"}); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(30 * time.Minute)
+	r, ok := c.Get("Jo@EXAMPLE.COM")
+	if !ok || r.Attempts != 0 {
+		t.Errorf("Get = %+v, %v, want a fresh result without attempts", r, ok)
+	}
+	if _, ok := c.Get("x@example.com"); ok {
+		t.Error("Get returned an unknown result past its TTL")
+	}
+
+	removed, err := c.Prune()
+	if err != nil || removed != 1 {
+		t.Errorf("Prune = %d, %v, want 1 removed", removed, err)
+	}
+}
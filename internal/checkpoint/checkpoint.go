@@ -0,0 +1,151 @@
+// Package checkpoint records the progress of a file validation run next to
+// its output, so an interrupted run can be resumed without re-validating
+// (and paying for) rows that were already written.
+package checkpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Checkpoint describes a file validation run in progress.
+type Checkpoint struct {
+	Input       string    `json:"input"`
+	InputSHA256 string    `json:"input_sha256"`
+	InputSize   int64     `json:"input_size"`
+	Output      string    `json:"output"`
+	RowsWritten int       `json:"rows_written"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// SplitBy and Parts describe a run writing one file per state or
+	// sub-state. Output is then the base name of those files, and Parts
+	// lists the ones created so far.
+	SplitBy string   `json:"split_by,omitempty"`
+	Parts   []string `json:"parts,omitempty"`
+
+	// Format is the output format: csv, json or jsonl. Empty means csv.
+	Format string `json:"format,omitempty"`
+
+	// Rejects is the file rows filtered out by --where went to, if any.
+	Rejects string `json:"rejects,omitempty"`
+
+	// FoldAliases records --fold-aliases, which changes the addresses
+	// validated and so which rows repeat earlier ones.
+	FoldAliases bool `json:"fold_aliases,omitempty"`
+
+	// FixTypos is the --fix-typos mode, if any.
+	FixTypos string `json:"fix_typos,omitempty"`
+}
+
+// ErrNotFound is returned by Load when there is no checkpoint for an output.
+var ErrNotFound = errors.New("no checkpoint found")
+
+// Path returns the checkpoint file path for an output file.
+func Path(output string) string {
+	return output + ".checkpoint"
+}
+
+// New returns a checkpoint for validating input into output, hashing the
+// input so a later resume can tell whether it changed.
+func New(input, output string) (*Checkpoint, error) {
+	sum, size, err := HashFile(input)
+	if err != nil {
+		return nil, err
+	}
+	return &Checkpoint{
+		Input:       input,
+		InputSHA256: sum,
+		InputSize:   size,
+		Output:      output,
+	}, nil
+}
+
+// Load reads the checkpoint for an output file.
+func Load(output string) (*Checkpoint, error) {
+	data, err := os.ReadFile(Path(output))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w for %s", ErrNotFound, output)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("could not parse checkpoint %s: %w", Path(output), err)
+	}
+	return &cp, nil
+}
+
+// Save writes the checkpoint atomically, so a crash mid-save leaves the
+// previous version in place.
+func (cp *Checkpoint) Save() error {
+	cp.UpdatedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode checkpoint: %w", err)
+	}
+
+	path := Path(cp.Output)
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Verify checks that input is the same file the checkpoint was made for.
+func (cp *Checkpoint) Verify(input string) error {
+	sum, size, err := HashFile(input)
+	if err != nil {
+		return err
+	}
+	if size != cp.InputSize || sum != cp.InputSHA256 {
+		return fmt.Errorf("%s has changed since the interrupted run — start over without --resume", input)
+	}
+	return nil
+}
+
+// Remove deletes the checkpoint for an output file, if there is one.
+func Remove(output string) error {
+	if err := os.Remove(Path(output)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not remove checkpoint: %w", err)
+	}
+	return nil
+}
+
+// HashFile returns the hex SHA-256 and size of a file.
+func HashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("could not open file: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("could not hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
@@ -0,0 +1,108 @@
+package checkpoint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.csv")
+	output := filepath.Join(dir, "out.csv")
+	writeFile(t, input, "email\njo@example.com\n")
+
+	cp, err := New(input, output)
+	if err != nil {
+		t.Fatalf("New = %v", err)
+	}
+	if cp.InputSize != 21 || len(cp.InputSHA256) != 64 {
+		t.Errorf("New = %+v, want the input's size and hash", cp)
+	}
+	cp.RowsWritten = 7
+	cp.SplitBy = "state"
+	cp.Parts = []string{"out_ok.csv"}
+	if err := cp.Save(); err != nil {
+		t.Fatalf("Save = %v", err)
+	}
+	cp.RowsWritten = 9
+	if err := cp.Save(); err != nil {
+		t.Fatalf("second Save = %v", err)
+	}
+
+	got, err := Load(output)
+	if err != nil {
+		t.Fatalf("Load = %v", err)
+	}
+	if got.RowsWritten != 9 || got.Input != input || got.InputSHA256 != cp.InputSHA256 ||
+		got.SplitBy != "state" || len(got.Parts) != 1 || got.UpdatedAt.IsZero() {
+		t.Errorf("Load = %+v, want %+v", got, cp)
+	}
+
+	// Saving replaces the file rather than leaving temporary copies.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("directory has %d files, want the input and the checkpoint", len(entries))
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.csv")
+	writeFile(t, input, "email\njo@example.com\n")
+	cp, err := New(input, filepath.Join(dir, "out.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cp.Verify(input); err != nil {
+		t.Errorf("Verify of the same input = %v", err)
+	}
+	// Same size, different contents.
+	writeFile(t, input, "email\nal@example.com\n")
+	if err := cp.Verify(input); err == nil {
+		t.Error("Verify of a changed input succeeded")
+	}
+	writeFile(t, input, "email\njo@example.com\nal@example.com\n")
+	if err := cp.Verify(input); err == nil {
+		t.Error("Verify of a longer input succeeded")
+	}
+	if err := cp.Verify(filepath.Join(dir, "missing.csv")); err == nil {
+		t.Error("Verify of a missing input succeeded")
+	}
+}
+
+func TestLoadRemove(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "out.csv")
+
+	if _, err := Load(output); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load without a checkpoint = %v, want ErrNotFound", err)
+	}
+
+	writeFile(t, Path(output), "{not json")
+	if _, err := Load(output); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Load of a corrupt checkpoint = %v, want a parse error", err)
+	}
+
+	if err := Remove(output); err != nil {
+		t.Fatalf("Remove = %v", err)
+	}
+	if _, err := Load(output); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load after Remove = %v, want ErrNotFound", err)
+	}
+	if err := Remove(output); err != nil {
+		t.Errorf("Remove without a checkpoint = %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MaxBatchSize is the largest number of addresses ValidateBatch sends in
+// one request.
+const MaxBatchSize = 100
+
+// BatchResult is the outcome for one address passed to ValidateBatch.
+// Exactly one of Result and Err is set.
+type BatchResult struct {
+	Email  string
+	Result *ValidationResult
+	Err    error
+}
+
+// verifyBatchRequest is the request body for multi-address verification.
+type verifyBatchRequest struct {
+	Emails []string `json:"emails"`
+}
+
+// ValidateBatch verifies up to MaxBatchSize addresses in a single request.
+// The returned slice has one entry per input, in input order. An error is
+// returned only when the request as a whole fails; addresses the API left
+// out of its response get a per-entry error wrapping ErrMissingResult.
+func (c *Client) ValidateBatch(ctx context.Context, emails []string) ([]BatchResult, error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+	if len(emails) > MaxBatchSize {
+		return nil, fmt.Errorf("batch of %d addresses exceeds the maximum of %d", len(emails), MaxBatchSize)
+	}
+
+	body := verifyBatchRequest{Emails: emails}
+	raw, attempts, err := c.doWithRetry(ctx, http.MethodPost, "/api/v1/verify_inline", body)
+	if err != nil {
+		return nil, withAttempts(err, attempts)
+	}
+
+	if raw.status < 200 || raw.status >= 300 {
+		return nil, withAttempts(newAPIError(raw), attempts)
+	}
+
+	var resp verifyResponse
+	if err := json.Unmarshal(raw.body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return matchBatchResults(emails, resp.Emails, attempts), nil
+}
+
+// matchBatchResults pairs API results with the addresses that were sent.
+// Results are matched by address, ignoring case and surrounding space;
+// results without an address fill the remaining inputs positionally.
+func matchBatchResults(emails []string, results []ValidationResult, attempts int) []BatchResult {
+	byAddress := make(map[string][]ValidationResult, len(results))
+	var anonymous []ValidationResult
+	for _, r := range results {
+		if r.Email == "" {
+			anonymous = append(anonymous, r)
+			continue
+		}
+		key := batchKey(r.Email)
+		byAddress[key] = append(byAddress[key], r)
+	}
+
+	out := make([]BatchResult, len(emails))
+	var unmatched []int
+	for i, email := range emails {
+		out[i].Email = email
+
+		key := batchKey(email)
+		matches := byAddress[key]
+		if len(matches) == 0 {
+			unmatched = append(unmatched, i)
+			continue
+		}
+
+		r := matches[0]
+		// Duplicate inputs may share a single result.
+		if len(matches) > 1 {
+			byAddress[key] = matches[1:]
+		}
+		r.Attempts = attempts
+		out[i].Result = &r
+	}
+
+	for _, i := range unmatched {
+		if len(anonymous) == 0 {
+			out[i].Err = fmt.Errorf("%w for %s", ErrMissingResult, emails[i])
+			continue
+		}
+		r := anonymous[0]
+		anonymous = anonymous[1:]
+		r.Email = emails[i]
+		r.Attempts = attempts
+		out[i].Result = &r
+	}
+
+	return out
+}
+
+func batchKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// Batch states reported by the API for server-side bulk jobs.
+const (
+	BatchPending    = "pending"
+	BatchProcessing = "processing"
+	BatchCompleted  = "completed"
+	BatchFailed     = "failed"
+	BatchCancelled  = "cancelled"
+)
+
+// Batch is a server-side bulk validation job.
+type Batch struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	State          string `json:"batch_state"`
+	EmailCount     int    `json:"email_count"`
+	ProcessedCount int    `json:"processed_count"`
+	OkCount        int    `json:"ok_count"`
+	InvalidCount   int    `json:"email_invalid_count"`
+	AcceptAllCount int    `json:"accept_all_count"`
+	UnknownCount   int    `json:"unknown_count"`
+	CreatedAt      string `json:"created_at"`
+	CompletedAt    string `json:"completed_at"`
+}
+
+// Done reports whether the batch has reached a terminal state.
+func (b *Batch) Done() bool {
+	switch b.State {
+	case BatchCompleted, BatchFailed, BatchCancelled:
+		return true
+	}
+	return false
+}
+
+// CreateBatch uploads a CSV file as a new bulk job. The file must have a
+// header row with an "email" column. The upload is sent once and never
+// retried: if only the response were lost, sending it again would create
+// a second billed job.
+func (c *Client) CreateBatch(ctx context.Context, name string, csvData io.Reader) (*Batch, error) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+
+	if err := form.WriteField("name", name); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if _, err := io.Copy(part, csvData); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	body := rawBody{contentType: form.FormDataContentType(), data: buf.Bytes()}
+	return parseBatch(c.doOnce(ctx, http.MethodPost, "/api/v1/batches", body))
+}
+
+// GetBatch returns the current status of a bulk job.
+func (c *Client) GetBatch(ctx context.Context, id string) (*Batch, error) {
+	return c.batchRequest(ctx, http.MethodGet, "/api/v1/batches/"+url.PathEscape(id), nil)
+}
+
+// CancelBatch stops a bulk job that has not finished yet.
+func (c *Client) CancelBatch(ctx context.Context, id string) (*Batch, error) {
+	return c.batchRequest(ctx, http.MethodPost, "/api/v1/batches/"+url.PathEscape(id)+"/cancel", nil)
+}
+
+// DownloadBatch returns the results of a completed bulk job.
+func (c *Client) DownloadBatch(ctx context.Context, id string) ([]ValidationResult, error) {
+	raw, attempts, err := c.doWithRetry(ctx, http.MethodGet, "/api/v1/batches/"+url.PathEscape(id)+"/results", nil)
+	if err != nil {
+		return nil, withAttempts(err, attempts)
+	}
+
+	if raw.status < 200 || raw.status >= 300 {
+		return nil, withAttempts(newAPIError(raw), attempts)
+	}
+
+	var resp verifyResponse
+	if err := json.Unmarshal(raw.body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return resp.Emails, nil
+}
+
+// batchRequest sends a request that is safe to repeat, such as reading or
+// cancelling a job, and parses the job it returns.
+func (c *Client) batchRequest(ctx context.Context, method, path string, body any) (*Batch, error) {
+	return parseBatch(c.doWithRetry(ctx, method, path, body))
+}
+
+// parseBatch parses the job in a response.
+func parseBatch(raw *apiResponse, attempts int, err error) (*Batch, error) {
+	if err != nil {
+		return nil, withAttempts(err, attempts)
+	}
+
+	if raw.status < 200 || raw.status >= 300 {
+		return nil, withAttempts(newAPIError(raw), attempts)
+	}
+
+	var batch Batch
+	if err := json.Unmarshal(raw.body, &batch); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &batch, nil
+}
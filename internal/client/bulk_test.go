@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestClient returns a client for a fake API served by handler. It
+// retries immediately and is not rate limited.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return New("test-key").
+		WithBaseURL(srv.URL).
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3}).
+		WithLimiter(NewTokenBucket(0, 1))
+}
+
+func TestCreateBatch(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/batches" {
+			t.Errorf("request = %s %s, want POST /api/v1/batches", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("parsing upload: %v", err)
+			return
+		}
+		if got := r.FormValue("name"); got != "contacts.csv" {
+			t.Errorf("name = %q, want contacts.csv", got)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("reading file part: %v", err)
+			return
+		}
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+		if header.Filename != "contacts.csv" || string(data) != "email\njo@example.com\n" {
+			t.Errorf("file = %q with contents %q", header.Filename, data)
+		}
+		io.WriteString(w, `{"id":"b1","name":"contacts.csv","batch_state":"pending","email_count":1}`)
+	})
+
+	batch, err := c.CreateBatch(context.Background(), "contacts.csv", strings.NewReader("email\njo@example.com\n"))
+	if err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+	if batch.ID != "b1" || batch.State != BatchPending || batch.EmailCount != 1 || batch.Done() {
+		t.Errorf("batch = %+v", batch)
+	}
+}
+
+func TestCreateBatchNotRetried(t *testing.T) {
+	// The job may have been created even though the response failed, so
+	// sending the upload again could create a second one.
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	_, err := c.CreateBatch(context.Background(), "contacts.csv", strings.NewReader("email\njo@example.com\n"))
+	if !errors.Is(err, ErrServer) {
+		t.Errorf("CreateBatch = %v, want a server error", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d requests, want 1", calls.Load())
+	}
+}
+
+func TestGetBatchRetried(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{"id":"b1","batch_state":"processing"}`)
+	})
+
+	batch, err := c.GetBatch(context.Background(), "b1")
+	if err != nil || batch.State != BatchProcessing {
+		t.Fatalf("GetBatch = %+v, %v", batch, err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("made %d requests, want 2", calls.Load())
+	}
+}
+
+func TestGetBatch(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.EscapedPath() != "/api/v1/batches/a%2Fb" {
+			t.Errorf("request = %s %s, want GET /api/v1/batches/a%%2Fb", r.Method, r.URL.EscapedPath())
+		}
+		io.WriteString(w, `{"id":"a/b","batch_state":"completed","email_count":3,"processed_count":3,"ok_count":2,"email_invalid_count":1}`)
+	})
+
+	batch, err := c.GetBatch(context.Background(), "a/b")
+	if err != nil {
+		t.Fatalf("GetBatch: %v", err)
+	}
+	if batch.ID != "a/b" || !batch.Done() || batch.ProcessedCount != 3 || batch.OkCount != 2 || batch.InvalidCount != 1 {
+		t.Errorf("batch = %+v", batch)
+	}
+}
+
+func TestGetBatchNotFound(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"error":"not_found","message":"no such batch"}`)
+	})
+
+	_, err := c.GetBatch(context.Background(), "nope")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("GetBatch = %v, want a 404 *APIError", err)
+	}
+}
+
+func TestDownloadBatch(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v1/batches/b1/results" {
+			t.Errorf("request = %s %s, want GET /api/v1/batches/b1/results", r.Method, r.URL.Path)
+		}
+		io.WriteString(w, `{"emails":[
+			{"address":"jo@example.com","domain":"example.com","email_state":"ok","email_sub_state":"email_ok"},
+			{"address":"bad@example.com","domain":"example.com","email_state":"email_invalid","email_sub_state":"failed_smtp_check"}
+		]}`)
+	})
+
+	results, err := c.DownloadBatch(context.Background(), "b1")
+	if err != nil {
+		t.Fatalf("DownloadBatch: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if r := results[1]; r.Email != "bad@example.com" || r.State != "email_invalid" || r.SubState != "failed_smtp_check" {
+		t.Errorf("results[1] = %+v", r)
+	}
+}
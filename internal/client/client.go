@@ -4,18 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
-	"sync"
+	"strconv"
 	"time"
 )
 
-const (
-	DefaultBaseURL = "https://api.truelist.io"
-	rateLimit      = 10 // requests per second
-)
+const DefaultBaseURL = "https://api.truelist.io"
 
 // ValidationResult holds the response from the Truelist API.
 type ValidationResult struct {
@@ -29,6 +28,82 @@ type ValidationResult struct {
 	SubState   string  `json:"email_sub_state"`
 	VerifiedAt string  `json:"verified_at"`
 	Suggestion *string `json:"did_you_mean"`
+
+	// Attempts is the number of HTTP requests it took to get this result.
+	// It is set by the client, not the API.
+	Attempts int `json:"attempts,omitempty"`
+
+	// Source names the local check that produced this result without
+	// calling the API, such as SourceSyntax. It is empty for API results.
+	Source string `json:"source,omitempty"`
+}
+
+// Sources of results settled by the CLI's local prechecks.
+const (
+	SourceSyntax     = "syntax"          // offline syntax check
+	SourceDisposable = "disposable_list" // disposable-domain list
+	SourceRole       = "role_list"       // role-name list
+	SourceDNS        = "dns"             // DNS MX lookup
+)
+
+// ResultFields names the fields of a ValidationResult that Field accepts:
+// the API's fields in the order it returns them, then the CLI's own.
+var ResultFields = []string{
+	"email",
+	"domain",
+	"canonical",
+	"mx_record",
+	"first_name",
+	"last_name",
+	"state",
+	"sub_state",
+	"verified_at",
+	"suggestion",
+	"attempts",
+	"source",
+}
+
+// Field returns the named field as a string. Nil fields are empty, as is
+// Attempts when it is zero. ok is false for an unknown name.
+func (r *ValidationResult) Field(name string) (value string, ok bool) {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+
+	switch name {
+	case "email":
+		return r.Email, true
+	case "domain":
+		return r.Domain, true
+	case "canonical":
+		return r.Canonical, true
+	case "mx_record":
+		return deref(r.MxRecord), true
+	case "first_name":
+		return deref(r.FirstName), true
+	case "last_name":
+		return deref(r.LastName), true
+	case "state":
+		return r.State, true
+	case "sub_state":
+		return r.SubState, true
+	case "verified_at":
+		return r.VerifiedAt, true
+	case "suggestion":
+		return deref(r.Suggestion), true
+	case "attempts":
+		if r.Attempts == 0 {
+			return "", true
+		}
+		return strconv.Itoa(r.Attempts), true
+	case "source":
+		return r.Source, true
+	default:
+		return "", false
+	}
 }
 
 // verifyResponse wraps the API response envelope.
@@ -51,16 +126,36 @@ type AccountPlan struct {
 	PaymentPlan string `json:"payment_plan"`
 }
 
+// RetryPolicy controls how transient failures (network errors, 429 and 5xx
+// responses) are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of requests made, including the first.
+	// Values below 1 disable retries.
+	MaxAttempts int
+	// MaxElapsed caps the total time spent retrying a single call. Zero
+	// means no limit.
+	MaxElapsed time.Duration
+	// BaseDelay is the backoff before the first retry; it doubles on each
+	// subsequent attempt up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultRetryPolicy is used by clients created with New.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	MaxElapsed:  2 * time.Minute,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
 // Client is the Truelist API client.
 type Client struct {
 	baseURL    string
 	apiKey     string
 	httpClient *http.Client
-
-	// Rate limiter fields.
-	mu        sync.Mutex
-	tokens    int
-	lastReset time.Time
+	retry      RetryPolicy
+	limiter    Limiter
 }
 
 // New creates a new API client.
@@ -71,8 +166,8 @@ func New(apiKey string) *Client {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		tokens:    rateLimit,
-		lastReset: time.Now(),
+		retry:   DefaultRetryPolicy,
+		limiter: NewTokenBucket(DefaultRateLimit, DefaultBurst),
 	}
 }
 
@@ -82,90 +177,183 @@ func (c *Client) WithBaseURL(url string) *Client {
 	return c
 }
 
-// waitForToken blocks until a rate limit token is available.
-func (c *Client) waitForToken() {
-	for {
-		c.mu.Lock()
-		now := time.Now()
-		elapsed := now.Sub(c.lastReset)
+// WithRetryPolicy overrides the default retry policy.
+func (c *Client) WithRetryPolicy(p RetryPolicy) *Client {
+	c.retry = p
+	return c
+}
 
-		if elapsed >= time.Second {
-			c.tokens = rateLimit
-			c.lastReset = now
-		}
+// WithLimiter overrides the default rate limiter. Pass a limiter shared
+// between clients to make them draw from the same budget.
+func (c *Client) WithLimiter(l Limiter) *Client {
+	c.limiter = l
+	return c
+}
 
-		if c.tokens > 0 {
-			c.tokens--
-			c.mu.Unlock()
-			return
-		}
-		c.mu.Unlock()
+// apiResponse is a fully-read HTTP response.
+type apiResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
 
-		sleepDuration := time.Second - elapsed
-		if sleepDuration < 10*time.Millisecond {
-			sleepDuration = 10 * time.Millisecond
-		}
-		time.Sleep(sleepDuration)
-	}
+// rawBody is a pre-encoded request body, such as a multipart form, that
+// doRequest sends as-is instead of marshaling to JSON.
+type rawBody struct {
+	contentType string
+	data        []byte
 }
 
 // doRequest performs an authenticated HTTP request.
-func (c *Client) doRequest(ctx context.Context, method, path string, body any) ([]byte, int, error) {
+func (c *Client) doRequest(ctx context.Context, method, path string, body any) (*apiResponse, error) {
 	var reqBody io.Reader
-	if body != nil {
+	contentType := "application/json"
+	switch b := body.(type) {
+	case nil:
+	case rawBody:
+		contentType = b.contentType
+		reqBody = bytes.NewReader(b.data)
+	default:
 		data, err := json.Marshal(body)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
 		reqBody = bytes.NewReader(data)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "truelist-cli")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, 0, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrTransport, err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	return respBody, resp.StatusCode, nil
+	return &apiResponse{status: resp.StatusCode, header: resp.Header, body: respBody}, nil
 }
 
-// Validate verifies a single email address.
-func (c *Client) Validate(ctx context.Context, email string) (*ValidationResult, error) {
-	c.waitForToken()
+// doWithRetry performs a rate-limited request, retrying network errors,
+// 429 and 5xx responses according to the client's retry policy. It returns
+// the last response or error along with the number of attempts made.
+func (c *Client) doWithRetry(ctx context.Context, method, path string, body any) (*apiResponse, int, error) {
+	start := time.Now()
+	attempt := 0
 
-	path := "/api/v1/verify_inline?email=" + url.QueryEscape(email)
-	body, status, err := c.doRequest(ctx, http.MethodPost, path, nil)
+	for {
+		attempt++
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, attempt, err
+		}
+
+		resp, err := c.doRequest(ctx, method, path, body)
+		if !shouldRetry(ctx, resp, err) || attempt >= c.retry.MaxAttempts {
+			return resp, attempt, err
+		}
+
+		delay := c.backoff(attempt)
+		if resp != nil {
+			if ra, ok := retryAfter(resp.header, time.Now()); ok {
+				delay = ra
+			}
+		}
+		if c.retry.MaxElapsed > 0 && time.Since(start)+delay > c.retry.MaxElapsed {
+			return resp, attempt, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, attempt, err
+		case <-timer.C:
+		}
+	}
+}
+
+// doOnce performs a single rate-limited request, for requests that must
+// not be repeated. It returns the attempt count like doWithRetry.
+func (c *Client) doOnce(ctx context.Context, method, path string, body any) (*apiResponse, int, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, 1, err
+	}
+	resp, err := c.doRequest(ctx, method, path, body)
+	return resp, 1, err
+}
+
+// shouldRetry reports whether a request outcome is a transient failure.
+func shouldRetry(ctx context.Context, resp *apiResponse, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
 	if err != nil {
-		return nil, err
+		return errors.Is(err, ErrTransport)
+	}
+	return resp.status == http.StatusTooManyRequests || resp.status >= 500
+}
+
+// backoff returns a jittered exponential delay before retry number attempt.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.retry.BaseDelay
+	for i := 1; i < attempt && d < c.retry.MaxDelay; i++ {
+		d *= 2
+	}
+	if c.retry.MaxDelay > 0 && d > c.retry.MaxDelay {
+		d = c.retry.MaxDelay
 	}
+	if d <= 0 {
+		return 0
+	}
+	// Equal jitter: pick uniformly from [d/2, d] so workers that failed
+	// together don't retry together.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
 
-	if status == 401 {
-		return nil, fmt.Errorf("unauthorized — check your API key")
+// retryAfter parses a Retry-After header given either as delay seconds or
+// as an HTTP date.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
 	}
-	if status == 429 {
-		return nil, fmt.Errorf("rate limited — too many requests")
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
 	}
-	if status < 200 || status >= 300 {
-		return nil, fmt.Errorf("API error (status %d): %s", status, string(body))
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// Validate verifies a single email address.
+func (c *Client) Validate(ctx context.Context, email string) (*ValidationResult, error) {
+	path := "/api/v1/verify_inline?email=" + url.QueryEscape(email)
+	raw, attempts, err := c.doWithRetry(ctx, http.MethodPost, path, nil)
+	if err != nil {
+		return nil, withAttempts(err, attempts)
+	}
+
+	if raw.status < 200 || raw.status >= 300 {
+		return nil, withAttempts(newAPIError(raw), attempts)
 	}
 
 	var resp verifyResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
+	if err := json.Unmarshal(raw.body, &resp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -177,28 +365,24 @@ func (c *Client) Validate(ctx context.Context, email string) (*ValidationResult,
 	if result.Email == "" {
 		result.Email = email
 	}
+	result.Attempts = attempts
 
 	return &result, nil
 }
 
 // Whoami checks the API key and returns account info.
 func (c *Client) Whoami(ctx context.Context) (*AccountInfo, error) {
-	c.waitForToken()
-
-	body, status, err := c.doRequest(ctx, http.MethodGet, "/me", nil)
+	raw, attempts, err := c.doWithRetry(ctx, http.MethodGet, "/me", nil)
 	if err != nil {
-		return nil, err
+		return nil, withAttempts(err, attempts)
 	}
 
-	if status == 401 {
-		return nil, fmt.Errorf("unauthorized — check your API key")
-	}
-	if status < 200 || status >= 300 {
-		return nil, fmt.Errorf("API error (status %d): %s", status, string(body))
+	if raw.status < 200 || raw.status >= 300 {
+		return nil, withAttempts(newAPIError(raw), attempts)
 	}
 
 	var info AccountInfo
-	if err := json.Unmarshal(body, &info); err != nil {
+	if err := json.Unmarshal(raw.body, &info); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -0,0 +1,240 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestValidateRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if got := r.URL.Query().Get("email"); got != "jo+x@example.com" {
+			t.Errorf("email = %q", got)
+		}
+		io.WriteString(w, `{"emails":[{"address":"jo+x@example.com","email_state":"ok"}]}`)
+	})
+
+	r, err := c.Validate(context.Background(), "jo+x@example.com")
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if r.State != "ok" || r.Attempts != 3 {
+		t.Errorf("result = %+v, want ok after 3 attempts", r)
+	}
+}
+
+func TestValidateGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	_, err := c.Validate(context.Background(), "jo@example.com")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Validate = %v, want a rate-limit error", err)
+	}
+	if n := calls.Load(); n != 3 || Attempts(err) != 3 {
+		t.Errorf("made %d requests, error reports %d attempts, want 3", n, Attempts(err))
+	}
+}
+
+func TestValidateNotRetried(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusPaymentRequired, http.StatusUnprocessableEntity} {
+		var calls atomic.Int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(status)
+		})
+
+		_, err := c.Validate(context.Background(), "jo@example.com")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != status {
+			t.Errorf("status %d: Validate = %v, want an APIError", status, err)
+		}
+		if calls.Load() != 1 {
+			t.Errorf("status %d: made %d requests, want 1", status, calls.Load())
+		}
+	}
+}
+
+func TestRetryAfterHonoured(t *testing.T) {
+	var calls atomic.Int32
+	var first time.Time
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if waited := time.Since(first); waited < 900*time.Millisecond {
+			t.Errorf("retried after %v, want Retry-After's 1s", waited)
+		}
+		io.WriteString(w, `{"email":"me@example.com"}`)
+	})
+
+	if _, err := c.Whoami(context.Background()); err != nil {
+		t.Fatalf("Whoami: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("made %d requests, want 2", calls.Load())
+	}
+}
+
+func TestMaxElapsed(t *testing.T) {
+	// The 1s Retry-After would overrun MaxElapsed, so the client gives up
+	// instead of waiting.
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	c.WithRetryPolicy(RetryPolicy{MaxAttempts: 5, MaxElapsed: 100 * time.Millisecond})
+
+	start := time.Now()
+	_, err := c.Validate(context.Background(), "jo@example.com")
+	if !errors.Is(err, ErrServer) {
+		t.Errorf("Validate = %v, want a server error", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d requests, want 1", calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("took %v, want it to give up without waiting", elapsed)
+	}
+}
+
+func TestRetryStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.AfterFunc(20*time.Millisecond, cancel)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	c.WithRetryPolicy(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Validate(ctx, "jo@example.com")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrServer) {
+			t.Errorf("Validate = %v, want the last server error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Validate still waiting to retry after the context was cancelled")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d requests, want 1", calls.Load())
+	}
+}
+
+func TestTransportErrorRetried(t *testing.T) {
+	c := New("test-key").
+		WithBaseURL("http://127.0.0.1:1").
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2}).
+		WithLimiter(NewTokenBucket(0, 1))
+
+	_, err := c.Validate(context.Background(), "jo@example.com")
+	if !errors.Is(err, ErrTransport) || Attempts(err) != 2 {
+		t.Errorf("Validate = %v after %d attempts, want a transport error after 2", err, Attempts(err))
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := New("test-key").WithRetryPolicy(RetryPolicy{
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  time.Second,
+	})
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{20, time.Second},
+	}
+	for _, tt := range tests {
+		for range 50 {
+			if d := c.backoff(tt.attempt); d < tt.max/2 || d > tt.max {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", tt.attempt, d, tt.max/2, tt.max)
+				break
+			}
+		}
+	}
+
+	if d := New("test-key").WithRetryPolicy(RetryPolicy{}).backoff(3); d != 0 {
+		t.Errorf("backoff without a base delay = %v, want 0", d)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"7", 7 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"Wed, 01 May 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Wednesday, 01-May-24 12:00:30 GMT", 30 * time.Second, true},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.value != "" {
+			h.Set("Retry-After", tt.value)
+		}
+		got, ok := retryAfter(h, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestValidateSendsAuth(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/verify_inline" {
+			t.Errorf("request = %s %s, want POST /api/v1/verify_inline", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		io.WriteString(w, `{"emails":[{"email_state":"email_invalid","email_sub_state":"failed_no_mailbox"}]}`)
+	})
+
+	r, err := c.Validate(context.Background(), "jo@example.com")
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if r.Email != "jo@example.com" || r.State != "email_invalid" || r.Attempts != 1 {
+		t.Errorf("result = %+v", r)
+	}
+	if got, _ := r.Field("attempts"); got != "1" {
+		t.Errorf("Field(attempts) = %q", got)
+	}
+}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors for classifying API failures with errors.Is.
+var (
+	ErrUnauthorized   = errors.New("unauthorized")
+	ErrQuotaExceeded  = errors.New("quota exceeded")
+	ErrRateLimited    = errors.New("rate limited")
+	ErrInvalidRequest = errors.New("invalid request")
+	ErrServer         = errors.New("server error")
+	ErrTransport      = errors.New("request failed")
+
+	// ErrMissingResult is returned by ValidateBatch for an address the API
+	// did not include in its response.
+	ErrMissingResult = errors.New("API returned no result")
+)
+
+// APIError is returned for any non-2xx response from the Truelist API.
+type APIError struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Code is the machine-readable error code from the response body, if any.
+	Code string
+	// Message is the human-readable message from the response body, if any.
+	Message string
+	// RequestID is the value of the X-Request-Id response header, if any.
+	RequestID string
+	// Retryable reports whether the request may succeed if sent again.
+	Retryable bool
+}
+
+func (e *APIError) Error() string {
+	switch {
+	case e.is(ErrUnauthorized):
+		return "unauthorized — check your API key"
+	case e.is(ErrQuotaExceeded):
+		if e.Message != "" {
+			return "quota exceeded — " + e.Message
+		}
+		return "quota exceeded — add credits to your Truelist account"
+	case e.is(ErrRateLimited):
+		return "rate limited — too many requests"
+	}
+
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, msg)
+}
+
+// Is lets errors.Is match an APIError against the sentinel errors.
+func (e *APIError) Is(target error) bool {
+	return e.is(target)
+}
+
+func (e *APIError) is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrQuotaExceeded:
+		return e.StatusCode == http.StatusPaymentRequired ||
+			e.Code == "quota_exceeded" || e.Code == "insufficient_credits"
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrInvalidRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// newAPIError builds an APIError from a non-2xx response.
+func newAPIError(resp *apiResponse) *APIError {
+	e := &APIError{
+		StatusCode: resp.status,
+		RequestID:  resp.header.Get("X-Request-Id"),
+		Retryable:  resp.status == http.StatusTooManyRequests || resp.status >= 500,
+	}
+	e.Code, e.Message = parseErrorBody(resp.body)
+	return e
+}
+
+// parseErrorBody extracts an error code and message from the common JSON
+// error envelopes: {"error": "msg"}, {"error": {"code", "message"}},
+// {"code", "message"} and {"errors": ["msg", ...]}. Non-JSON bodies are
+// used as the message verbatim.
+func parseErrorBody(body []byte) (code, message string) {
+	var env struct {
+		Error   json.RawMessage   `json:"error"`
+		Errors  []json.RawMessage `json:"errors"`
+		Code    string            `json:"code"`
+		Message string            `json:"message"`
+	}
+	if err := json.Unmarshal(body, &env); err != nil {
+		return "", strings.TrimSpace(string(body))
+	}
+
+	code, message = env.Code, env.Message
+	if len(env.Error) > 0 {
+		var s string
+		var obj struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(env.Error, &s) == nil {
+			message = s
+		} else if json.Unmarshal(env.Error, &obj) == nil {
+			code, message = obj.Code, obj.Message
+		}
+	}
+	if message == "" && len(env.Errors) > 0 {
+		var msgs []string
+		for _, raw := range env.Errors {
+			var s string
+			if json.Unmarshal(raw, &s) == nil {
+				msgs = append(msgs, s)
+			}
+		}
+		message = strings.Join(msgs, "; ")
+	}
+	return code, message
+}
+
+// RetryError is returned when a request still fails after the retry policy
+// has been exhausted.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%s (after %d attempts)", e.Err, e.Attempts)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// Attempts reports how many requests were made before err was returned.
+func Attempts(err error) int {
+	var re *RetryError
+	if errors.As(err, &re) {
+		return re.Attempts
+	}
+	return 1
+}
+
+// withAttempts wraps err in a RetryError when more than one attempt was made.
+func withAttempts(err error, attempts int) error {
+	if attempts <= 1 {
+		return err
+	}
+	return &RetryError{Attempts: attempts, Err: err}
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseErrorBody(t *testing.T) {
+	tests := []struct {
+		body          string
+		code, message string
+	}{
+		{`{"error":"Email is required"}`, "", "Email is required"},
+		{`{"error":{"code":"quota_exceeded","message":"No credits left"}}`, "quota_exceeded", "No credits left"},
+		{`{"code":"invalid_email","message":"Bad address"}`, "invalid_email", "Bad address"},
+		{`{"errors":["first","second"]}`, "", "first; second"},
+		{`{"errors":[{"detail":"x"},"only strings"]}`, "", "only strings"},
+		{`{"message":"kept","errors":["ignored"]}`, "", "kept"},
+		{`{}`, "", ""},
+		{"Bad Gateway\n", "", "Bad Gateway"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		code, message := parseErrorBody([]byte(tt.body))
+		if code != tt.code || message != tt.message {
+			t.Errorf("parseErrorBody(%q) = %q, %q, want %q, %q", tt.body, code, message, tt.code, tt.message)
+		}
+	}
+}
+
+func TestNewAPIError(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-Request-Id", "req-1")
+	e := newAPIError(&apiResponse{
+		status: http.StatusUnprocessableEntity,
+		header: h,
+		body:   []byte(`{"error":{"code":"invalid_email","message":"bad input"}}`),
+	})
+	if e.StatusCode != 422 || e.Code != "invalid_email" || e.Message != "bad input" || e.RequestID != "req-1" || e.Retryable {
+		t.Errorf("newAPIError = %+v", e)
+	}
+	if !errors.Is(e, ErrInvalidRequest) || errors.Is(e, ErrServer) {
+		t.Errorf("%v should match only ErrInvalidRequest", e)
+	}
+	if got := e.Error(); got != "API error (status 422): bad input" {
+		t.Errorf("Error = %q", got)
+	}
+
+	e = newAPIError(&apiResponse{status: http.StatusServiceUnavailable, header: http.Header{}})
+	if !e.Retryable || !errors.Is(e, ErrServer) || !strings.Contains(e.Error(), "Service Unavailable") {
+		t.Errorf("newAPIError for an empty 503 = %+v (%v)", e, e)
+	}
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	sentinels := []error{ErrUnauthorized, ErrQuotaExceeded, ErrRateLimited, ErrInvalidRequest, ErrServer}
+	tests := []struct {
+		err  *APIError
+		want error // nil when none match
+	}{
+		{&APIError{StatusCode: 401}, ErrUnauthorized},
+		{&APIError{StatusCode: 403}, ErrUnauthorized},
+		{&APIError{StatusCode: 402}, ErrQuotaExceeded},
+		{&APIError{StatusCode: 429}, ErrRateLimited},
+		{&APIError{StatusCode: 400}, ErrInvalidRequest},
+		{&APIError{StatusCode: 500}, ErrServer},
+		{&APIError{StatusCode: 502}, ErrServer},
+		{&APIError{StatusCode: 404}, nil},
+	}
+	for _, tt := range tests {
+		for _, s := range sentinels {
+			if got := errors.Is(tt.err, s); got != (s == tt.want) {
+				t.Errorf("errors.Is(status %d, %v) = %v", tt.err.StatusCode, s, got)
+			}
+		}
+	}
+
+	// A quota code makes an otherwise invalid request a quota error too.
+	e := &APIError{StatusCode: 400, Code: "insufficient_credits", Message: "top up"}
+	if !errors.Is(e, ErrQuotaExceeded) || e.Error() != "quota exceeded — top up" {
+		t.Errorf("%+v: Is(ErrQuotaExceeded) = %v, Error = %q", e, errors.Is(e, ErrQuotaExceeded), e.Error())
+	}
+}
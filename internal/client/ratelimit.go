@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRateLimit is the API's documented limit in requests per second.
+	DefaultRateLimit = 10
+	// DefaultBurst is the number of requests that may be sent back to back
+	// before the rate limit applies.
+	DefaultBurst = 1
+)
+
+// Limiter gates outgoing requests. Wait blocks until a request may be sent
+// and returns early with the context's error if ctx is done first.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// TokenBucket is a Limiter that refills at a fixed rate up to a maximum
+// burst. It is safe for concurrent use, and waiters are served in the
+// order they arrive.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a full bucket allowing rate requests per second
+// with bursts of up to burst requests. A rate of zero or less disables
+// limiting; a burst below 1 is treated as 1.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait reserves a token, sleeping until it is available. If ctx is done
+// before then, the reservation is returned to the bucket.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if b.rate <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	// Take the token now, even if that leaves the bucket in debt, so later
+	// callers queue up behind this one.
+	b.tokens--
+	wait := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
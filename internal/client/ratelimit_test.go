@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// timeWait returns how long b.Wait took.
+func timeWait(t *testing.T, b *TokenBucket) time.Duration {
+	t.Helper()
+	start := time.Now()
+	if err := b.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait = %v", err)
+	}
+	return time.Since(start)
+}
+
+func TestTokenBucketBurst(t *testing.T) {
+	b := NewTokenBucket(10, 3)
+	for i := range 3 {
+		if d := timeWait(t, b); d > 20*time.Millisecond {
+			t.Errorf("request %d of the burst waited %v", i+1, d)
+		}
+	}
+	if d := timeWait(t, b); d < 80*time.Millisecond {
+		t.Errorf("request after the burst waited %v, want about 100ms", d)
+	}
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	b := NewTokenBucket(20, 2)
+	timeWait(t, b)
+	timeWait(t, b)
+
+	// Refills at one token per 50ms, up to the burst.
+	time.Sleep(60 * time.Millisecond)
+	if d := timeWait(t, b); d > 20*time.Millisecond {
+		t.Errorf("refilled token waited %v", d)
+	}
+	if d := timeWait(t, b); d < 20*time.Millisecond {
+		t.Errorf("second request after one refill waited %v, want about 40ms", d)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+	timeWait(t, b)
+	timeWait(t, b)
+	if d := timeWait(t, b); d < 30*time.Millisecond {
+		t.Errorf("bucket refilled past its burst: third request waited %v", d)
+	}
+}
+
+func TestTokenBucketConcurrent(t *testing.T) {
+	b := NewTokenBucket(50, 1)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := b.Wait(context.Background()); err != nil {
+				t.Errorf("Wait = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	// One request at once, then one every 20ms.
+	if d := time.Since(start); d < 70*time.Millisecond {
+		t.Errorf("5 requests at 50/s took %v, want at least 80ms", d)
+	}
+}
+
+func TestTokenBucketUnlimited(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		b := NewTokenBucket(rate, 0)
+		start := time.Now()
+		for range 1000 {
+			if err := b.Wait(context.Background()); err != nil {
+				t.Fatalf("Wait = %v", err)
+			}
+		}
+		if d := time.Since(start); d > 100*time.Millisecond {
+			t.Errorf("rate %v: 1000 requests took %v, want no limit", rate, d)
+		}
+	}
+}
+
+func TestTokenBucketCancelled(t *testing.T) {
+	b := NewTokenBucket(10, 1)
+	timeWait(t, b)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := b.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait = %v, want the context's error", err)
+	}
+	if d := time.Since(start); d > 60*time.Millisecond {
+		t.Errorf("cancelled Wait returned after %v, want about 20ms", d)
+	}
+
+	// The cancelled waiter gave its token back, so the next one waits only
+	// for the rest of the first refill, not for a second.
+	if d := timeWait(t, b); d > 150*time.Millisecond {
+		t.Errorf("Wait after a cancelled one took %v, want about 80ms", d)
+	}
+
+	done, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewTokenBucket(10, 5).Wait(done); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait with a done context = %v, want context.Canceled", err)
+	}
+}
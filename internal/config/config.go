@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,8 +9,20 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// ErrNoAPIKey is returned by GetAPIKey when no API key is configured.
+var ErrNoAPIKey = errors.New("no API key configured — run `truelist config set api-key <key>` or set TRUELIST_API_KEY")
+
 type Config struct {
 	APIKey string `yaml:"api_key"`
+
+	// RateLimit and RateBurst override the client's default rate limiter.
+	// Zero means use the default.
+	RateLimit float64 `yaml:"rate_limit,omitempty"`
+	RateBurst int     `yaml:"rate_burst,omitempty"`
+
+	// CacheTTL overrides how long cached results stay fresh, keyed by
+	// email state, e.g. {"ok": "30d"}.
+	CacheTTL map[string]string `yaml:"cache_ttl,omitempty"`
 }
 
 // Dir returns the config directory path (~/.config/truelist).
@@ -33,9 +46,24 @@ func FilePath() (string, error) {
 // Load reads the config file and merges with environment variables.
 // Precedence: config file > TRUELIST_API_KEY env var.
 func Load() (*Config, error) {
+	cfg, err := LoadFile()
+	if err != nil {
+		return nil, err
+	}
+
+	// Fall back to env var if config file didn't provide an API key.
+	if cfg.APIKey == "" {
+		cfg.APIKey = os.Getenv("TRUELIST_API_KEY")
+	}
+
+	return cfg, nil
+}
+
+// LoadFile reads the config file without applying environment variables.
+// Use it when the config will be saved back to disk.
+func LoadFile() (*Config, error) {
 	cfg := &Config{}
 
-	// Try loading from file first.
 	fp, err := FilePath()
 	if err == nil {
 		data, readErr := os.ReadFile(fp)
@@ -44,11 +72,6 @@ func Load() (*Config, error) {
 		}
 	}
 
-	// Fall back to env var if config file didn't provide an API key.
-	if cfg.APIKey == "" {
-		cfg.APIKey = os.Getenv("TRUELIST_API_KEY")
-	}
-
 	return cfg, nil
 }
 
@@ -83,7 +106,7 @@ func GetAPIKey() (string, error) {
 		return "", err
 	}
 	if cfg.APIKey == "" {
-		return "", fmt.Errorf("no API key configured — run `truelist config set api-key <key>` or set TRUELIST_API_KEY")
+		return "", ErrNoAPIKey
 	}
 	return cfg.APIKey, nil
 }
@@ -0,0 +1,133 @@
+// Package dnscheck finds domains that cannot receive email by looking up
+// their MX records, falling back to A and AAAA records the way mail
+// servers do (RFC 5321 section 5.1).
+package dnscheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolver looks up DNS records. *net.Resolver implements it; tests can
+// use a fake.
+type Resolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// Status is what a lookup found out about a domain.
+type Status int
+
+const (
+	// Unknown means the lookup failed, for example by timing out, so the
+	// domain may still receive email.
+	Unknown Status = iota
+	// Live means the domain has MX records, or A or AAAA records to
+	// deliver to instead.
+	Live
+	// Dead means the domain does not exist, has no records to deliver to,
+	// or has a null MX record saying it accepts no email (RFC 7505).
+	Dead
+)
+
+// Checker checks domains, looking each one up only once. It is safe for
+// concurrent use; callers checking a domain that is being looked up wait
+// for that lookup instead of starting another.
+type Checker struct {
+	resolver Resolver
+	timeout  time.Duration
+
+	mu      sync.Mutex
+	domains map[string]*lookup
+}
+
+type lookup struct {
+	done   chan struct{}
+	status Status
+}
+
+// New returns a Checker that gives each domain's lookups up to timeout.
+func New(r Resolver, timeout time.Duration) *Checker {
+	return &Checker{resolver: r, timeout: timeout, domains: make(map[string]*lookup)}
+}
+
+// Check returns the status of an ASCII domain name.
+func (c *Checker) Check(ctx context.Context, domain string) Status {
+	domain = strings.ToLower(domain)
+
+	c.mu.Lock()
+	l, ok := c.domains[domain]
+	if !ok {
+		l = &lookup{done: make(chan struct{})}
+		c.domains[domain] = l
+	}
+	c.mu.Unlock()
+
+	if ok {
+		select {
+		case <-l.done:
+			return l.status
+		case <-ctx.Done():
+			return Unknown
+		}
+	}
+
+	l.status = c.resolve(ctx, domain)
+	close(l.done)
+	return l.status
+}
+
+// Dead returns the number of dead domains found so far.
+func (c *Checker) Dead() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, l := range c.domains {
+		select {
+		case <-l.done:
+			if l.status == Dead {
+				n++
+			}
+		default:
+		}
+	}
+	return n
+}
+
+func (c *Checker) resolve(ctx context.Context, domain string) Status {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	// A trailing dot stops the resolver from trying search domains.
+	mxs, err := c.resolver.LookupMX(ctx, domain+".")
+	switch {
+	case err == nil && len(mxs) == 1 && (mxs[0].Host == "." || mxs[0].Host == ""):
+		return Dead
+	case err == nil && len(mxs) > 0:
+		return Live
+	case err != nil && !isNotFound(err):
+		return Unknown
+	}
+
+	// Without MX records, mail goes to the domain's own addresses.
+	_, err = c.resolver.LookupHost(ctx, domain+".")
+	switch {
+	case err == nil:
+		return Live
+	case isNotFound(err):
+		return Dead
+	default:
+		return Unknown
+	}
+}
+
+// isNotFound reports whether a lookup failed because the name or its
+// records don't exist, rather than because the lookup itself failed.
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
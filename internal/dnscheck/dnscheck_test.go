@@ -0,0 +1,95 @@
+package dnscheck
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeResolver answers from fixed records. A name without MX or host
+// records does not exist, and names in hang block until the lookup's
+// context is done.
+type fakeResolver struct {
+	mx    map[string][]*net.MX
+	hosts map[string][]string
+	hang  map[string]bool
+	calls atomic.Int32 // MX lookups made
+}
+
+func (r *fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	r.calls.Add(1)
+	if r.hang[name] {
+		<-ctx.Done()
+		return nil, &net.DNSError{Err: "i/o timeout", Name: name, IsTimeout: true}
+	}
+	if mxs, ok := r.mx[name]; ok {
+		return mxs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if addrs, ok := r.hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestCheck(t *testing.T) {
+	r := &fakeResolver{
+		mx: map[string][]*net.MX{
+			"mail.example.":   {{Host: "mx1.mail.example.", Pref: 10}},
+			"nomail.example.": {{Host: ".", Pref: 0}},
+		},
+		hosts: map[string][]string{
+			"hostonly.example.": {"192.0.2.1"},
+			"v6only.example.":   {"2001:db8::1"},
+		},
+		hang: map[string]bool{"slow.example.": true},
+	}
+	c := New(r, 50*time.Millisecond)
+
+	tests := []struct {
+		domain string
+		want   Status
+	}{
+		{"mail.example", Live},
+		{"MAIL.Example", Live},
+		{"nomail.example", Dead},
+		{"hostonly.example", Live},
+		{"v6only.example", Live},
+		{"missing.example", Dead},
+		{"slow.example", Unknown},
+	}
+	for _, tt := range tests {
+		if got := c.Check(context.Background(), tt.domain); got != tt.want {
+			t.Errorf("Check(%q) = %v, want %v", tt.domain, got, tt.want)
+		}
+	}
+	if got := c.Dead(); got != 2 {
+		t.Errorf("Dead() = %d, want 2", got)
+	}
+}
+
+func TestCheckLooksUpOnce(t *testing.T) {
+	r := &fakeResolver{mx: map[string][]*net.MX{"example.com.": {{Host: "mx.example.com."}}}}
+	c := New(r, time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := c.Check(context.Background(), "example.com"); got != Live {
+				t.Errorf("Check = %v, want Live", got)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := r.calls.Load(); got != 1 {
+		t.Errorf("made %d MX lookups, want 1", got)
+	}
+}
@@ -0,0 +1,321 @@
+// Package filter implements the expression language used by --where to
+// select validation results.
+//
+// An expression compares result fields with string values:
+//
+//	state == "ok" and sub_state not in ["is_disposable", "is_role"]
+//	state == accept_all and domain != gmail.com
+//	domain like "*.edu" or email matches "^info@"
+//
+// Comparisons are ==, !=, in [...], like (a glob pattern) and matches (a
+// regular expression), each of which can be negated with "not". They are
+// combined with and, or, not and parentheses; &&, || and ! also work. A
+// field on its own is true when it is not empty. Values may be quoted with
+// single or double quotes, or left bare when they are a single word.
+//
+// ==, != and in ignore case, as does like. matches is case-sensitive
+// unless the pattern starts with (?i).
+package filter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+)
+
+// aliases are alternative field names, matching the API's JSON names.
+var aliases = map[string]string{
+	"address":         "email",
+	"email_state":     "state",
+	"email_sub_state": "sub_state",
+	"did_you_mean":    "suggestion",
+}
+
+// fieldGetter returns a function reading the named field of a result, or
+// nil if there is no such field.
+func fieldGetter(name string) func(r *client.ValidationResult) string {
+	if !slices.Contains(client.ResultFields, name) {
+		return nil
+	}
+	return func(r *client.ValidationResult) string {
+		v, _ := r.Field(name)
+		return v
+	}
+}
+
+// Expr is a parsed filter expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+// String returns the expression's source.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Match reports whether a result satisfies the expression.
+func (e *Expr) Match(r *client.ValidationResult) bool {
+	return e.root.eval(r)
+}
+
+// Parse parses a filter expression.
+func Parse(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %s", t)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// node is an expression tree node.
+type node interface {
+	eval(r *client.ValidationResult) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(r *client.ValidationResult) bool { return n.left.eval(r) && n.right.eval(r) }
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(r *client.ValidationResult) bool { return n.left.eval(r) || n.right.eval(r) }
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(r *client.ValidationResult) bool { return !n.operand.eval(r) }
+
+// presentNode is a field used on its own.
+type presentNode struct {
+	field func(*client.ValidationResult) string
+}
+
+func (n presentNode) eval(r *client.ValidationResult) bool { return n.field(r) != "" }
+
+// matchNode compares a field with a predicate.
+type matchNode struct {
+	field func(*client.ValidationResult) string
+	match func(string) bool
+}
+
+func (n matchNode) eval(r *client.ValidationResult) bool { return n.match(n.field(r)) }
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is one of the given operators or
+// keywords.
+func (p *parser) accept(words ...string) bool {
+	t := p.peek()
+	if t.kind != tokOp && t.kind != tokWord {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(t.text, w) {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) errorf(t token, format string, a ...any) error {
+	return fmt.Errorf("at position %d: %s", t.pos+1, fmt.Sprintf(format, a...))
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or", "||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and", "&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("not", "!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	if p.accept("(") {
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokOp || t.text != ")" {
+			return nil, p.errorf(t, "expected ) but found %s", t)
+		}
+		return n, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	t := p.next()
+	if t.kind != tokWord {
+		return nil, p.errorf(t, "expected a field name but found %s", t)
+	}
+	name := strings.ToLower(t.text)
+	if alias, ok := aliases[name]; ok {
+		name = alias
+	}
+	field := fieldGetter(name)
+	if field == nil {
+		return nil, p.errorf(t, "unknown field %q (fields: %s)", t.text, strings.Join(client.ResultFields, ", "))
+	}
+
+	switch {
+	case p.accept("==", "="):
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return matchNode{field, func(s string) bool { return strings.EqualFold(s, v) }}, nil
+	case p.accept("!="):
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return matchNode{field, func(s string) bool { return !strings.EqualFold(s, v) }}, nil
+	}
+
+	negate := p.accept("not")
+	var n node
+	var err error
+	switch {
+	case p.accept("in"):
+		n, err = p.parseIn(field)
+	case p.accept("like"):
+		n, err = p.parseLike(field)
+	case p.accept("matches"):
+		n, err = p.parseMatches(field)
+	case negate:
+		return nil, p.errorf(p.peek(), "expected in, like or matches after not")
+	default:
+		return presentNode{field}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if negate {
+		return notNode{n}, nil
+	}
+	return n, nil
+}
+
+// parseValue parses a quoted string or a bare word.
+func (p *parser) parseValue() (string, error) {
+	t := p.next()
+	if t.kind != tokString && t.kind != tokWord {
+		return "", p.errorf(t, "expected a value but found %s", t)
+	}
+	return t.text, nil
+}
+
+func (p *parser) parseIn(field func(*client.ValidationResult) string) (node, error) {
+	open := p.next()
+	if open.kind != tokOp || (open.text != "[" && open.text != "(") {
+		return nil, p.errorf(open, "expected [ after in but found %s", open)
+	}
+	closer := "]"
+	if open.text == "(" {
+		closer = ")"
+	}
+
+	set := make(map[string]bool)
+	for {
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		set[strings.ToLower(v)] = true
+
+		t := p.next()
+		if t.kind == tokOp && t.text == closer {
+			break
+		}
+		if t.kind != tokOp || t.text != "," {
+			return nil, p.errorf(t, "expected , or %s but found %s", closer, t)
+		}
+	}
+	return matchNode{field, func(s string) bool { return set[strings.ToLower(s)] }}, nil
+}
+
+func (p *parser) parseLike(field func(*client.ValidationResult) string) (node, error) {
+	at := p.peek()
+	pattern, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	pattern = strings.ToLower(pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, p.errorf(at, "invalid glob %q", pattern)
+	}
+	return matchNode{field, func(s string) bool {
+		ok, _ := path.Match(pattern, strings.ToLower(s))
+		return ok
+	}}, nil
+}
+
+func (p *parser) parseMatches(field func(*client.ValidationResult) string) (node, error) {
+	at := p.peek()
+	pattern, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, p.errorf(at, "invalid regular expression: %s", err)
+	}
+	return matchNode{field, re.MatchString}, nil
+}
@@ -0,0 +1,87 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+)
+
+func TestMatch(t *testing.T) {
+	suggestion := "jo@gmail.com"
+	ok := &client.ValidationResult{Email: "Info@Example.edu", Domain: "example.edu", State: "ok", SubState: "email_ok"}
+	role := &client.ValidationResult{Email: "jo@gmial.com", Domain: "gmial.com", State: "email_invalid", SubState: "is_role", Suggestion: &suggestion}
+
+	tests := []struct {
+		expr     string
+		ok, role bool // whether each result matches
+	}{
+		{`state == "ok"`, true, false},
+		{`state = OK`, true, false},
+		{`email_state == 'ok'`, true, false},
+		{`state != ok`, false, true},
+		{`sub_state in ["is_disposable", "is_role"]`, false, true},
+		{`sub_state not in (is_disposable, is_role)`, true, false},
+		{`domain like "*.edu"`, true, false},
+		{`domain like *.EDU`, true, false},
+		{`domain not like "*.edu"`, false, true},
+		{`email matches "^info@"`, false, false},
+		{`email matches "(?i)^info@"`, true, false},
+		{`suggestion`, false, true},
+		{`did_you_mean == jo@gmail.com`, false, true},
+		{`!suggestion`, true, false},
+		{`state == ok or sub_state == is_role`, true, true},
+		{`state == ok and sub_state == is_role`, false, false},
+		{`state == ok || state == email_invalid && domain == nope`, true, false},
+		{`(state == ok || state == email_invalid) && domain == gmial.com`, false, true},
+		{`not (state == ok)`, false, true},
+		{`state == "a \"quoted\" value"`, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) = %v", tt.expr, err)
+			}
+			if e.String() != tt.expr {
+				t.Errorf("String() = %q, want %q", e.String(), tt.expr)
+			}
+			if got := e.Match(ok); got != tt.ok {
+				t.Errorf("Match(ok result) = %v, want %v", got, tt.ok)
+			}
+			if got := e.Match(role); got != tt.role {
+				t.Errorf("Match(role result) = %v, want %v", got, tt.role)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{``, "at position 1: expected a field name but found end of expression"},
+		{`colour == red`, `at position 1: unknown field "colour"`},
+		{`state ==`, "at position 9: expected a value but found end of expression"},
+		{`state == ok and`, "at position 16: expected a field name"},
+		{`state == ok state`, `at position 13: unexpected "state"`},
+		{`(state == ok`, "at position 13: expected ) but found end of expression"},
+		{`state not ok`, "at position 11: expected in, like or matches after not"},
+		{`state in ok`, `at position 10: expected [ after in but found "ok"`},
+		{`state in [ok, bad`, "expected , or ] but found end of expression"},
+		{`state in (ok]`, `expected , or ) but found "]"`},
+		{`domain like "[a"`, `at position 13: invalid glob "[a"`},
+		{`email matches "("`, "at position 15: invalid regular expression"},
+		{`state == "ok`, "at position 10: unterminated string"},
+		{`state == ok; rm`, `at position 12: unexpected ';'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Parse(%q) = %v, want an error containing %q", tt.expr, err, tt.err)
+			}
+		})
+	}
+}
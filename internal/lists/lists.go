@@ -0,0 +1,256 @@
+// Package lists holds the lists of domains and names used by local checks:
+// disposable domains and role names for the precheck, and popular provider
+// domains for typo correction.
+//
+// Each list is embedded in the binary. `truelist lists update` saves a
+// newer copy in the config directory, which is used instead from then on.
+// Lists are plain text: one entry per line, with blank lines and lines
+// starting with # ignored.
+package lists
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/config"
+	"golang.org/x/net/idna"
+)
+
+// The list names.
+const (
+	Disposable = "disposable"
+	Roles      = "roles"
+	Providers  = "providers"
+)
+
+// Names lists every list name.
+var Names = []string{Disposable, Roles, Providers}
+
+var (
+	//go:embed disposable.txt
+	embeddedDisposable []byte
+	//go:embed roles.txt
+	embeddedRoles []byte
+	//go:embed providers.txt
+	embeddedProviders []byte
+)
+
+// List is a loaded list.
+type List struct {
+	Name string
+	// Path is the updated copy the list was loaded from, or empty for the
+	// embedded list.
+	Path string
+	// UpdatedAt is when the updated copy was saved; zero for the embedded
+	// list.
+	UpdatedAt time.Time
+
+	entries []string
+	set     map[string]bool
+}
+
+// Len returns the number of entries.
+func (l *List) Len() int {
+	return len(l.entries)
+}
+
+// Entries returns the entries in the order they appear in the list.
+func (l *List) Entries() []string {
+	return l.entries
+}
+
+// Contains reports whether entry is on the list. Entries are compared in
+// lowercase.
+func (l *List) Contains(entry string) bool {
+	return l.set[strings.ToLower(entry)]
+}
+
+// ContainsDomain reports whether domain, or a domain it is a subdomain
+// of, is on the list.
+func (l *List) ContainsDomain(domain string) bool {
+	domain = strings.ToLower(domain)
+	for {
+		if l.set[domain] {
+			return true
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok || !strings.Contains(parent, ".") {
+			return false
+		}
+		domain = parent
+	}
+}
+
+// Path returns where the updated copy of a list is kept.
+func Path(name string) (string, error) {
+	if err := checkName(name); err != nil {
+		return "", err
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "lists", name+".txt"), nil
+}
+
+// Load returns a list, preferring an updated copy over the embedded one.
+func Load(name string) (*List, error) {
+	path, err := Path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		entries, err := Parse(name, embedded(name))
+		if err != nil {
+			return nil, fmt.Errorf("embedded %s list: %w", name, err)
+		}
+		return newList(name, "", entries), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s list: %w", name, err)
+	}
+
+	entries, err := Parse(name, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	l := newList(name, path, entries)
+	if fi, err := os.Stat(path); err == nil {
+		l.UpdatedAt = fi.ModTime()
+	}
+	return l, nil
+}
+
+// Update checks data as the new contents of a list and saves it in place
+// of the current copy. It returns the number of entries.
+func Update(name string, data []byte) (int, error) {
+	entries, err := Parse(name, data)
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, fmt.Errorf("the new %s list is empty", name)
+	}
+
+	path, err := Path(name)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return 0, fmt.Errorf("could not create lists directory: %w", err)
+	}
+
+	// Write the normalized entries to a temporary file and rename it over
+	// the old copy, so a failed update leaves the old list intact.
+	tmp, err := os.CreateTemp(filepath.Dir(path), name+"-*.txt")
+	if err != nil {
+		return 0, fmt.Errorf("could not save %s list: %w", name, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(strings.Join(entries, "\n") + "\n"); err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("could not save %s list: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("could not save %s list: %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("could not save %s list: %w", name, err)
+	}
+	return len(entries), nil
+}
+
+// Reset deletes the updated copy of a list, going back to the embedded one.
+func Reset(name string) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not reset %s list: %w", name, err)
+	}
+	return nil
+}
+
+// Parse reads the entries of a list. Domains are lowercased and converted
+// to punycode; role names are lowercased. Repeated entries are dropped.
+func Parse(name string, data []byte) ([]string, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+
+	var entries []string
+	seen := make(map[string]bool)
+	for i, line := range strings.Split(string(data), "\n") {
+		entry := strings.TrimSpace(line)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		var err error
+		if name != Roles {
+			entry, err = parseDomain(entry)
+		} else {
+			entry, err = parseRole(entry)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if !seen[entry] {
+			seen[entry] = true
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func parseDomain(entry string) (string, error) {
+	// Accept "@example.com" and "*.example.com" as written by other lists.
+	entry = strings.TrimPrefix(strings.TrimPrefix(entry, "@"), "*.")
+	ascii, err := idna.Lookup.ToASCII(entry)
+	if err != nil || !strings.Contains(ascii, ".") {
+		return "", fmt.Errorf("%q is not a domain", entry)
+	}
+	return ascii, nil
+}
+
+func parseRole(entry string) (string, error) {
+	entry = strings.TrimSuffix(entry, "@")
+	if strings.ContainsAny(entry, " \t@\"") {
+		return "", fmt.Errorf("%q is not a role name", entry)
+	}
+	return strings.ToLower(entry), nil
+}
+
+func checkName(name string) error {
+	if name != Disposable && name != Roles && name != Providers {
+		return fmt.Errorf("unknown list %q (lists: %s)", name, strings.Join(Names, ", "))
+	}
+	return nil
+}
+
+func embedded(name string) []byte {
+	switch name {
+	case Disposable:
+		return embeddedDisposable
+	case Roles:
+		return embeddedRoles
+	default:
+		return embeddedProviders
+	}
+}
+
+func newList(name, path string, entries []string) *List {
+	set := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		set[e] = true
+	}
+	return &List{Name: name, Path: path, entries: entries, set: set}
+}
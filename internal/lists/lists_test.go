@@ -0,0 +1,128 @@
+package lists
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := "# comment\n\nExample.COM\n@mail.example\n*.temp.example\nbücher.de\nexample.com\n  spaced.example  \n"
+	got, err := Parse(Disposable, []byte(data))
+	if err != nil {
+		t.Fatalf("Parse = %v", err)
+	}
+	want := []string{"example.com", "mail.example", "temp.example", "xn--bcher-kva.de", "spaced.example"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Parse = %q, want %q", got, want)
+	}
+
+	got, err = Parse(Roles, []byte("Info\nadmin@\n# comment\nINFO\n"))
+	if err != nil {
+		t.Fatalf("Parse = %v", err)
+	}
+	if want := []string{"info", "admin"}; !slices.Equal(got, want) {
+		t.Errorf("Parse = %q, want %q", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name, data, err string
+	}{
+		{Disposable, "example.com\nlocalhost\n", `line 2: "localhost" is not a domain`},
+		{Providers, "bad_domain.com\n", `line 1: "bad_domain.com" is not a domain`},
+		{Roles, "info\nno reply\n", `line 2: "no reply" is not a role name`},
+		{Roles, "jo@example.com\n", `line 1: "jo@example.com" is not a role name`},
+		{"colours", "red\n", `unknown list "colours"`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.name, []byte(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Parse(%s, %q) = %v, want an error containing %q", tt.name, tt.data, err, tt.err)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	l := newList(Disposable, "", []string{"mailinator.com", "temp.co.uk"})
+	tests := []struct {
+		domain string
+		want   bool
+	}{
+		{"mailinator.com", true},
+		{"MAILINATOR.com", true},
+		{"a.b.mailinator.com", true},
+		{"temp.co.uk", true},
+		{"x.temp.co.uk", true},
+		{"notmailinator.com", false},
+		{"co.uk", false},
+		{"com", false},
+	}
+	for _, tt := range tests {
+		if got := l.ContainsDomain(tt.domain); got != tt.want {
+			t.Errorf("ContainsDomain(%q) = %v, want %v", tt.domain, got, tt.want)
+		}
+	}
+	if !l.Contains("Mailinator.COM") || l.Contains("a.mailinator.com") {
+		t.Error("Contains should match whole entries, ignoring case")
+	}
+}
+
+func TestLoadUpdateReset(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	embedded, err := Load(Roles)
+	if err != nil {
+		t.Fatalf("Load = %v", err)
+	}
+	if embedded.Path != "" || !embedded.UpdatedAt.IsZero() || !embedded.Contains("info") {
+		t.Fatalf("embedded list = %+v", embedded)
+	}
+
+	if _, err := Update(Roles, []byte("# nothing\n")); err == nil {
+		t.Error("Update with an empty list succeeded")
+	}
+	if _, err := Update(Roles, []byte("sales\nno reply\n")); err == nil {
+		t.Error("Update with an invalid entry succeeded")
+	}
+	n, err := Update(Roles, []byte("Sales\nsupport\nsales\n"))
+	if err != nil || n != 2 {
+		t.Fatalf("Update = %d, %v, want 2 entries", n, err)
+	}
+
+	updated, err := Load(Roles)
+	if err != nil {
+		t.Fatalf("Load = %v", err)
+	}
+	if updated.Path == "" || updated.UpdatedAt.IsZero() {
+		t.Errorf("updated list = %+v, want a path and a time", updated)
+	}
+	if want := []string{"sales", "support"}; !slices.Equal(updated.Entries(), want) {
+		t.Errorf("Entries = %q, want %q", updated.Entries(), want)
+	}
+
+	if err := Reset(Roles); err != nil {
+		t.Fatalf("Reset = %v", err)
+	}
+	if _, err := os.Stat(updated.Path); !os.IsNotExist(err) {
+		t.Errorf("updated copy still there after Reset: %v", err)
+	}
+	if err := Reset(Roles); err != nil {
+		t.Errorf("second Reset = %v", err)
+	}
+	again, err := Load(Roles)
+	if err != nil || again.Len() != embedded.Len() {
+		t.Errorf("Load after Reset = %d entries, %v, want the embedded %d", again.Len(), err, embedded.Len())
+	}
+}
+
+func TestEmbeddedListsParse(t *testing.T) {
+	for _, name := range Names {
+		entries, err := Parse(name, embedded(name))
+		if err != nil || len(entries) == 0 {
+			t.Errorf("embedded %s list: %d entries, %v", name, len(entries), err)
+		}
+	}
+}
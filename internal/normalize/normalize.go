@@ -0,0 +1,202 @@
+// Package normalize turns email addresses into canonical keys for
+// comparing, caching and deduplicating them, and cleans up addresses as
+// they appear in input files.
+package normalize
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/width"
+)
+
+// Key normalizes an address for comparison: surrounding space is trimmed
+// and the domain is lowercased. The local part is left as-is, since it is
+// case-sensitive in principle.
+func Key(email string) string {
+	email = strings.TrimSpace(email)
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	return email[:at] + strings.ToLower(email[at:])
+}
+
+// Options controls how Address normalizes.
+type Options struct {
+	// FoldAliases folds provider-specific aliases into the mailbox they
+	// deliver to, such as J.Doe+news@gmail.com into jdoe@gmail.com.
+	FoldAliases bool
+}
+
+// Address extracts the address from an input value and normalizes it:
+//
+//   - full-width characters, as typed with some East Asian input methods,
+//     are folded to their ASCII forms;
+//   - the address is taken out of display-name and mailto forms, such as
+//     "Jane Doe <jane@example.com>" and "mailto:jane@example.com";
+//   - surrounding brackets and quotes and trailing punctuation are removed;
+//   - the domain is lowercased and internationalized domains are converted
+//     to punycode.
+//
+// Anything that is still not an address is returned as it is by then, for
+// validation to reject. The local part is only changed by FoldAliases.
+func Address(s string, opts Options) string {
+	s = width.Fold.String(s)
+	s = extract(strings.TrimSpace(s))
+	s = trim(s)
+
+	at := strings.LastIndex(s, "@")
+	if at < 0 {
+		return s
+	}
+	local, domain := s[:at], Domain(s[at+1:])
+	if opts.FoldAliases {
+		local, domain = foldAlias(local, domain)
+	}
+	return local + "@" + domain
+}
+
+// Domain lowercases a domain and converts it to punycode if it is
+// internationalized. An address literal such as [192.0.2.1], or a domain
+// that is not valid, is only lowercased.
+func Domain(domain string) string {
+	if strings.HasPrefix(domain, "[") {
+		return strings.ToLower(domain)
+	}
+	ascii, err := idna.Lookup.ToASCII(domain)
+	if err != nil || !sameLabels(domain, ascii) {
+		return strings.ToLower(domain)
+	}
+	return ascii
+}
+
+// ideographicDots are the full stops the IDNA mapping turns into dots.
+var ideographicDots = strings.NewReplacer("\u3002", ".", "\uff0e", ".", "\uff61", ".")
+
+// sameLabels reports whether converting domain to ascii kept all of its
+// labels. The conversion drops a label such as xn-- or a lone soft hyphen
+// without an error, which would make a different address.
+func sameLabels(domain, ascii string) bool {
+	labels := strings.Split(ascii, ".")
+	if len(labels) != strings.Count(ideographicDots.Replace(domain), ".")+1 {
+		return false
+	}
+	return !slices.Contains(labels, "")
+}
+
+// extract takes the address out of a display-name form, a trailing
+// comment or a mailto: link.
+func extract(s string) string {
+	// Jane Doe <jane@example.com>: the last angle-bracketed part holding an
+	// @ is the address.
+	if open := strings.LastIndex(s, "<"); open >= 0 {
+		inner := s[open+1:]
+		if end := strings.Index(inner, ">"); end >= 0 {
+			inner = inner[:end]
+		}
+		if strings.Contains(inner, "@") {
+			s = strings.TrimSpace(inner)
+		}
+	}
+
+	// jane@example.com (Jane Doe)
+	if strings.HasSuffix(s, ")") {
+		if open := strings.LastIndex(s, " ("); open > 0 && strings.Contains(s[:open], "@") {
+			s = strings.TrimSpace(s[:open])
+		}
+	}
+
+	if len(s) > len("mailto:") && strings.EqualFold(s[:len("mailto:")], "mailto:") {
+		s = s[len("mailto:"):]
+		if q := strings.Index(s, "?"); q >= 0 {
+			s = s[:q]
+		}
+	}
+	return s
+}
+
+// brackets pairs the opening brackets and quotes that wrap addresses in
+// running text with their closing ones.
+var brackets = map[byte]byte{'(': ')', '[': ']', '{': '}', '<': '>', '"': '"', '\'': '\''}
+
+// trim removes trailing punctuation and any brackets or quotes wrapping an
+// address, as in "(jane@example.com)," copied out of a sentence. No domain
+// ends in punctuation, so only the end needs care: a bracket there is kept
+// if it closes an address literal.
+func trim(s string) string {
+	for {
+		before := s
+		s = strings.TrimRight(s, ".,;:!?")
+		if len(s) >= 2 && brackets[s[0]] != 0 && brackets[s[0]] == s[len(s)-1] && !isQuotedLocal(s) {
+			s = strings.TrimSpace(s[1 : len(s)-1])
+		}
+		if s != "" {
+			switch s[len(s)-1] {
+			case ')', '}', '>', '\'', '"':
+				s = s[:len(s)-1]
+			case ']':
+				if !strings.Contains(s, "@[") {
+					s = s[:len(s)-1]
+				}
+			}
+		}
+		if s == before {
+			return s
+		}
+	}
+}
+
+// isQuotedLocal reports whether s, which starts and ends with a quote,
+// begins with a quoted local part rather than being wrapped in quotes.
+func isQuotedLocal(s string) bool {
+	return s[0] == '"' && strings.Contains(s, `"@`) && !strings.HasSuffix(s, `"@`)
+}
+
+// aliasRule describes how a provider's mailboxes receive mail sent to
+// variants of their address.
+type aliasRule struct {
+	dots   bool   // dots in the local part are ignored
+	tag    byte   // a tag after this character is ignored
+	domain string // the provider's main domain, if this is another
+}
+
+// aliasRules lists the providers whose aliases FoldAliases folds.
+var aliasRules = map[string]aliasRule{
+	"gmail.com":      {dots: true, tag: '+'},
+	"googlemail.com": {dots: true, tag: '+', domain: "gmail.com"},
+	"outlook.com":    {tag: '+'},
+	"hotmail.com":    {tag: '+'},
+	"live.com":       {tag: '+'},
+	"msn.com":        {tag: '+'},
+	"icloud.com":     {tag: '+'},
+	"me.com":         {tag: '+'},
+	"mac.com":        {tag: '+'},
+	"fastmail.com":   {tag: '+'},
+	"proton.me":      {tag: '+'},
+	"protonmail.com": {tag: '+'},
+	"pm.me":          {tag: '+'},
+}
+
+// foldAlias folds an address at a known provider into the mailbox it
+// delivers to. These providers ignore case in local parts, so the local
+// part is lowercased too.
+func foldAlias(local, domain string) (string, string) {
+	rule, ok := aliasRules[domain]
+	if !ok || strings.HasPrefix(local, `"`) {
+		return local, domain
+	}
+	if rule.tag != 0 {
+		if i := strings.IndexByte(local, rule.tag); i > 0 {
+			local = local[:i]
+		}
+	}
+	if rule.dots {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	if rule.domain != "" {
+		domain = rule.domain
+	}
+	return strings.ToLower(local), domain
+}
@@ -0,0 +1,44 @@
+package normalize
+
+import "testing"
+
+func TestDomain(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Example.COM", "example.com"},
+		{"Bücher.de", "xn--bcher-kva.de"},
+		{"例え。jp", "xn--r8jz45g.jp"},
+		{"[IPv6:2001:DB8::1]", "[ipv6:2001:db8::1]"},
+		// Conversions that would drop a label keep the domain as typed.
+		{"xn--.com", "xn--.com"},
+		{"\u00ad.Example.com", "\u00ad.example.com"},
+		{"a..com", "a..com"},
+		{"Ex ample.com", "ex ample.com"},
+	}
+	for _, tt := range tests {
+		if got := Domain(tt.in); got != tt.want {
+			t.Errorf("Domain(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAddress(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"  Jo@Example.COM ", "Jo@example.com"},
+		{"Jo Smith <jo@example.com>", "jo@example.com"},
+		{"jo@example.com (Jo Smith)", "jo@example.com"},
+		{"mailto:jo@example.com?subject=hi", "jo@example.com"},
+		{"(jo@example.com),", "jo@example.com"},
+		{"ｊｏ＠ｅｘａｍｐｌｅ．ｃｏｍ", "jo@example.com"},
+		{"a@xn--.com", "a@xn--.com"},
+		{"not an address", "not an address"},
+	}
+	for _, tt := range tests {
+		if got := Address(tt.in, Options{}); got != tt.want {
+			t.Errorf("Address(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package output
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -48,6 +50,31 @@ func PrintValidationResult(w io.Writer, r *client.ValidationResult) {
 	if r.Suggestion != nil && *r.Suggestion != "" {
 		fmt.Fprintf(w, "  %-14s %s\n", dim.Sprint("Suggestion:"), cyan.Sprint(*r.Suggestion))
 	}
+
+	if r.Attempts > 1 {
+		fmt.Fprintf(w, "  %-14s %d\n", dim.Sprint("Attempts:"), r.Attempts)
+	}
+
+	if r.Source != "" {
+		fmt.Fprintf(w, "  %-14s %s\n", dim.Sprint("Source:"), cyan.Sprint(SourceDescription(r.Source)))
+	}
+}
+
+// SourceDescription describes where a result settled without the API came
+// from.
+func SourceDescription(source string) string {
+	switch source {
+	case client.SourceSyntax:
+		return "local syntax check (no API call)"
+	case client.SourceDisposable:
+		return "local disposable-domain list (no API call)"
+	case client.SourceRole:
+		return "local role-name list (no API call)"
+	case client.SourceDNS:
+		return "DNS lookup: the domain can't receive email (no API call)"
+	default:
+		return source
+	}
 }
 
 // PrintValidationJSON writes the result as JSON.
@@ -57,20 +84,204 @@ func PrintValidationJSON(w io.Writer, r *client.ValidationResult) error {
 	return enc.Encode(r)
 }
 
+// PrintValidationJSONL writes a result as one line of compact JSON.
+func PrintValidationJSONL(w io.Writer, r *client.ValidationResult) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// ErrorRecord stands in for a result in JSON output when validating an
+// address failed. Its email_state is always "error", so consumers can
+// tell the two apart with the same field.
+type ErrorRecord struct {
+	Email     string `json:"address"`
+	State     string `json:"email_state"`
+	Error     string `json:"error"`
+	Code      string `json:"error_code,omitempty"`
+	Status    int    `json:"status,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	Attempts  int    `json:"attempts,omitempty"`
+}
+
+// NewErrorRecord describes a failed validation of email.
+func NewErrorRecord(email string, err error) ErrorRecord {
+	rec := ErrorRecord{
+		Email:    email,
+		State:    "error",
+		Error:    err.Error(),
+		Attempts: client.Attempts(err),
+	}
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		rec.Code = apiErr.Code
+		rec.Status = apiErr.StatusCode
+		rec.RequestID = apiErr.RequestID
+	}
+	return rec
+}
+
+// PrintErrorJSONL writes an error record for email as one line of compact
+// JSON.
+func PrintErrorJSONL(w io.Writer, email string, err error) error {
+	return json.NewEncoder(w).Encode(NewErrorRecord(email, err))
+}
+
+// RowRecord is one row of a CSV file and its outcome, as written by
+// `validate --file` in JSON formats. Result and Error are both nil for a
+// row without an email.
+type RowRecord struct {
+	Row Row `json:"row"`
+	// Original is the address before --fix-typos rewrite corrected it, and
+	// Correction the correction --fix-typos annotate found for it.
+	Original   string                   `json:"original,omitempty"`
+	Correction string                   `json:"correction,omitempty"`
+	Result     *client.ValidationResult `json:"result,omitempty"`
+	Error      *ErrorRecord             `json:"error,omitempty"`
+}
+
+// Row is a CSV row keyed by its header. It marshals as a JSON object with
+// the keys in column order.
+type Row struct {
+	Header []string
+	Values []string
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r Row) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range r.Header {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		value := ""
+		if i < len(r.Values) {
+			value = r.Values[i]
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
 // PrintValidationQuiet writes just the state string.
 func PrintValidationQuiet(w io.Writer, r *client.ValidationResult) {
 	fmt.Fprintln(w, r.State)
 }
 
+// Summary holds the counts shown at the end of a bulk run.
+type Summary struct {
+	Total     int
+	OK        int
+	Invalid   int
+	AcceptAll int
+	Unknown   int
+
+	// Cached is how many results came from the local cache.
+	Cached int
+
+	// Local is how many results were settled by a local precheck.
+	Local int
+
+	// DNS is how many of the Local results came from the DNS check, each
+	// a credit saved, on DeadDomains domains that can't receive email.
+	DNS         int
+	DeadDomains int
+
+	// Rejected is how many results were filtered out by --where.
+	Rejected int
+
+	// Failed is how many rows could not be validated.
+	Failed int
+
+	// Typos is how many rows had a misspelled domain, which were corrected
+	// before validating if TyposFixed is set.
+	Typos      int
+	TyposFixed bool
+
+	// Rows and Unique count input rows and the distinct addresses among
+	// them when duplicates were validated once. Zero when not tracked.
+	Rows   int
+	Unique int
+
+	// Files lists the output files when results were split across several.
+	Files []string
+}
+
 // PrintSummary writes a validation batch summary.
-func PrintSummary(w io.Writer, total, ok, invalid, acceptAll, unknown int) {
+func PrintSummary(w io.Writer, s Summary) {
 	fmt.Fprintln(w)
 	bold.Fprintln(w, "Summary")
-	fmt.Fprintf(w, "  Total:      %d\n", total)
-	green.Fprintf(w, "  OK:         %d\n", ok)
-	red.Fprintf(w, "  Invalid:    %d\n", invalid)
-	yellow.Fprintf(w, "  Accept All: %d\n", acceptAll)
-	dim.Fprintf(w, "  Unknown:    %d\n", unknown)
+	fmt.Fprintf(w, "  Total:      %d\n", s.Total)
+	green.Fprintf(w, "  OK:         %d\n", s.OK)
+	red.Fprintf(w, "  Invalid:    %d\n", s.Invalid)
+	yellow.Fprintf(w, "  Accept All: %d\n", s.AcceptAll)
+	dim.Fprintf(w, "  Unknown:    %d\n", s.Unknown)
+	if s.Failed > 0 {
+		red.Fprintf(w, "  Failed:     %d\n", s.Failed)
+	}
+
+	if s.Cached > 0 {
+		cyan.Fprintf(w, "  Cached:     %d\n", s.Cached)
+	}
+	if s.Local > 0 {
+		cyan.Fprintf(w, "  Prechecked: %d\n", s.Local)
+	}
+	if s.DNS > 0 {
+		cyan.Fprintf(w, "  DNS check:  %d credits saved (%d dead domains)\n", s.DNS, s.DeadDomains)
+	}
+	if s.Rejected > 0 {
+		fmt.Fprintf(w, "  Filtered:   %d\n", s.Rejected)
+	}
+	if s.Typos > 0 {
+		verb := "found"
+		if s.TyposFixed {
+			verb = "corrected"
+		}
+		fmt.Fprintf(w, "  Typos:      %d %s\n", s.Typos, verb)
+	}
+	if s.Rows > 0 {
+		fmt.Fprintf(w, "  Rows:       %d (%d unique addresses)\n", s.Rows, s.Unique)
+	}
+	if len(s.Files) > 0 {
+		fmt.Fprintln(w, "  Files:")
+		for _, f := range s.Files {
+			fmt.Fprintf(w, "    %s\n", f)
+		}
+	}
+}
+
+// Suggestion is a correction offered by `truelist suggest`. Suggestion is
+// nil when there is none.
+type Suggestion struct {
+	Input      string  `json:"input"`
+	Suggestion *string `json:"suggestion"`
+}
+
+// PrintSuggestion writes a suggestion as "input → correction", or says
+// there is none.
+func PrintSuggestion(w io.Writer, s Suggestion) {
+	if s.Suggestion == nil {
+		fmt.Fprintf(w, "%s %s\n", s.Input, dim.Sprint("(no suggestion)"))
+		return
+	}
+	fmt.Fprintf(w, "%s → %s\n", s.Input, yellow.Sprint(*s.Suggestion))
+}
+
+// PrintSuggestionsJSON writes suggestions as a JSON array.
+func PrintSuggestionsJSON(w io.Writer, s []Suggestion) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
 }
 
 // PrintAccountInfo writes account details.
@@ -83,9 +294,51 @@ func PrintAccountInfo(w io.Writer, info *client.AccountInfo) {
 	fmt.Fprintf(w, "  %-12s %s\n", dim.Sprint("Plan:"), info.Account.PaymentPlan)
 }
 
-// PrintError writes a user-friendly error message.
-func PrintError(w io.Writer, msg string) {
-	red.Fprintf(w, "Error: %s\n", msg)
+// PrintBatch writes the status of a bulk job.
+func PrintBatch(w io.Writer, b *client.Batch) {
+	bold.Fprintf(w, "Batch %s\n", b.ID)
+	if b.Name != "" {
+		fmt.Fprintf(w, "  %-12s %s\n", dim.Sprint("Name:"), b.Name)
+	}
+	fmt.Fprintf(w, "  %-12s %s\n", dim.Sprint("State:"), batchStateColorized(b.State))
+
+	progress := fmt.Sprintf("%d / %d", b.ProcessedCount, b.EmailCount)
+	if b.EmailCount > 0 {
+		progress += fmt.Sprintf(" (%d%%)", b.ProcessedCount*100/b.EmailCount)
+	}
+	fmt.Fprintf(w, "  %-12s %s\n", dim.Sprint("Progress:"), progress)
+
+	if b.ProcessedCount > 0 {
+		green.Fprintf(w, "  %-12s %d\n", "OK:", b.OkCount)
+		red.Fprintf(w, "  %-12s %d\n", "Invalid:", b.InvalidCount)
+		yellow.Fprintf(w, "  %-12s %d\n", "Accept All:", b.AcceptAllCount)
+		dim.Fprintf(w, "  %-12s %d\n", "Unknown:", b.UnknownCount)
+	}
+
+	if b.CreatedAt != "" {
+		fmt.Fprintf(w, "  %-12s %s\n", dim.Sprint("Created At:"), b.CreatedAt)
+	}
+	if b.CompletedAt != "" {
+		fmt.Fprintf(w, "  %-12s %s\n", dim.Sprint("Completed:"), b.CompletedAt)
+	}
+}
+
+// PrintBatchJSON writes the status of a bulk job as JSON.
+func PrintBatchJSON(w io.Writer, b *client.Batch) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// PrintError writes a user-friendly error message. API errors also show
+// the request ID, if the API sent one, for support requests.
+func PrintError(w io.Writer, err error) {
+	red.Fprintf(w, "Error: %s\n", err)
+
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) && apiErr.RequestID != "" {
+		dim.Fprintf(w, "  Request ID: %s\n", apiErr.RequestID)
+	}
 }
 
 func stateIcon(state string) (string, *color.Color) {
@@ -101,15 +354,30 @@ func stateIcon(state string) (string, *color.Color) {
 	}
 }
 
+func batchStateColorized(state string) string {
+	switch state {
+	case client.BatchCompleted:
+		return green.Sprint(state)
+	case client.BatchFailed, client.BatchCancelled:
+		return red.Sprint(state)
+	default:
+		return yellow.Sprint(state)
+	}
+}
+
 func stateColorized(state string) string {
+	return stateColor(state).Sprint(state)
+}
+
+func stateColor(state string) *color.Color {
 	switch strings.ToLower(state) {
 	case "ok":
-		return green.Sprint(state)
-	case "email_invalid":
-		return red.Sprint(state)
+		return green
+	case "email_invalid", "error":
+		return red
 	case "accept_all":
-		return yellow.Sprint(state)
+		return yellow
 	default:
-		return dim.Sprint(state)
+		return dim
 	}
 }
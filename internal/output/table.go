@@ -0,0 +1,209 @@
+package output
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/client"
+	"github.com/fatih/color"
+	"github.com/rivo/uniseg"
+	"golang.org/x/term"
+)
+
+// tableGap separates table columns.
+const tableGap = "  "
+
+// TableColumn describes a column of a Table.
+type TableColumn struct {
+	Header string
+	// Width is the column's preferred width. Longer values are truncated.
+	Width int
+	// Min is the narrowest the column gets when the table is fitted to the
+	// terminal.
+	Min int
+	// Color, if set, picks the color of each value.
+	Color func(value string) *color.Color
+}
+
+// Table writes rows as aligned columns. Column widths are fixed when the
+// table is created, so rows can be written as they arrive rather than all
+// at the end.
+type Table struct {
+	w           io.Writer
+	cols        []TableColumn
+	widths      []int
+	wroteHeader bool
+}
+
+// NewTable returns a table writing to w. If maxWidth is positive, columns
+// are narrowed towards their minimum widths until the table fits in it.
+func NewTable(w io.Writer, cols []TableColumn, maxWidth int) *Table {
+	widths := make([]int, len(cols))
+	total := len(tableGap) * (len(cols) - 1)
+	for i, c := range cols {
+		widths[i] = max(c.Width, uniseg.StringWidth(c.Header))
+		total += widths[i]
+	}
+
+	// Take one cell at a time from whichever column has the most to spare.
+	for maxWidth > 0 && total > maxWidth {
+		widest, most := -1, 0
+		for i, c := range cols {
+			if spare := widths[i] - max(c.Min, 1); spare > most {
+				widest, most = i, spare
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return &Table{w: w, cols: cols, widths: widths}
+}
+
+// WriteRow writes a row, preceded by the header if it is the first.
+func (t *Table) WriteRow(values ...string) error {
+	var b strings.Builder
+	t.header(&b)
+	t.format(&b, values, func(i int, value string) *color.Color {
+		if t.cols[i].Color == nil {
+			return nil
+		}
+		return t.cols[i].Color(value)
+	})
+	_, err := io.WriteString(t.w, b.String())
+	return err
+}
+
+// Flush writes the header if no rows were written, so an empty table still
+// shows its columns.
+func (t *Table) Flush() error {
+	var b strings.Builder
+	t.header(&b)
+	_, err := io.WriteString(t.w, b.String())
+	return err
+}
+
+// header writes the header line unless it has been written already.
+func (t *Table) header(b *strings.Builder) {
+	if t.wroteHeader {
+		return
+	}
+	headers := make([]string, len(t.cols))
+	for i, c := range t.cols {
+		headers[i] = c.Header
+	}
+	t.format(b, headers, func(int, string) *color.Color { return bold })
+	t.wroteHeader = true
+}
+
+// format writes one line of cells, truncated and padded to their widths.
+func (t *Table) format(b *strings.Builder, values []string, colorOf func(i int, value string) *color.Color) {
+	var line strings.Builder
+	for i := range t.cols {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		cell := truncate(value, t.widths[i])
+		if c := colorOf(i, value); c != nil {
+			line.WriteString(c.Sprint(cell))
+		} else {
+			line.WriteString(cell)
+		}
+		if i < len(t.cols)-1 {
+			line.WriteString(strings.Repeat(" ", t.widths[i]-uniseg.StringWidth(cell)))
+			line.WriteString(tableGap)
+		}
+	}
+	b.WriteString(strings.TrimRight(line.String(), " "))
+	b.WriteByte('\n')
+}
+
+// truncate shortens s to fit in width cells, marking the cut with "…".
+func truncate(s string, width int) string {
+	if uniseg.StringWidth(s) <= width {
+		return s
+	}
+	var b strings.Builder
+	used := 0
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		w := g.Width()
+		if used+w > width-1 {
+			break
+		}
+		b.WriteString(g.Str())
+		used += w
+	}
+	b.WriteString("…")
+	return b.String()
+}
+
+// TerminalWidth returns the width of the terminal f is attached to, or 0 if
+// it is not a terminal.
+func TerminalWidth(f *os.File) int {
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// DefaultTableFields are the result fields shown by a ResultTable unless
+// others are chosen.
+var DefaultTableFields = []string{"email", "state", "sub_state", "domain", "suggestion", "source"}
+
+// resultColumns describes each result field as a table column.
+var resultColumns = map[string]TableColumn{
+	"email":       {Header: "EMAIL", Width: 36, Min: 16},
+	"domain":      {Header: "DOMAIN", Width: 24, Min: 10},
+	"canonical":   {Header: "CANONICAL", Width: 24, Min: 10},
+	"mx_record":   {Header: "MX RECORD", Width: 28, Min: 10},
+	"first_name":  {Header: "FIRST NAME", Width: 14, Min: 6},
+	"last_name":   {Header: "LAST NAME", Width: 14, Min: 6},
+	"state":       {Header: "STATE", Width: 13, Min: 13, Color: stateColor},
+	"sub_state":   {Header: "SUB-STATE", Width: 22, Min: 10},
+	"verified_at": {Header: "VERIFIED AT", Width: 20, Min: 10},
+	"suggestion":  {Header: "SUGGESTION", Width: 24, Min: 10},
+	"attempts":    {Header: "ATTEMPTS", Width: 8, Min: 8},
+	"source":      {Header: "SOURCE", Width: 15, Min: 6},
+}
+
+// ResultTable writes validation results as a table, one row per address.
+type ResultTable struct {
+	t      *Table
+	fields []string
+}
+
+// NewResultTable returns a table of the given result fields, which must be
+// names from client.ResultFields.
+func NewResultTable(w io.Writer, fields []string, maxWidth int) *ResultTable {
+	cols := make([]TableColumn, len(fields))
+	for i, f := range fields {
+		cols[i] = resultColumns[f]
+	}
+	return &ResultTable{t: NewTable(w, cols, maxWidth), fields: fields}
+}
+
+// Add writes a result's row.
+func (rt *ResultTable) Add(r *client.ValidationResult) error {
+	values := make([]string, len(rt.fields))
+	for i, f := range rt.fields {
+		values[i], _ = r.Field(f)
+	}
+	return rt.t.WriteRow(values...)
+}
+
+// AddError writes a row for an address that failed to validate, with the
+// state "error" and the error message as its sub-state.
+func (rt *ResultTable) AddError(email string, err error) error {
+	return rt.Add(&client.ValidationResult{Email: email, State: "error", SubState: err.Error()})
+}
+
+// Flush writes the header if no rows were written.
+func (rt *ResultTable) Flush() error {
+	return rt.t.Flush()
+}
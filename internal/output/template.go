@@ -0,0 +1,73 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are the helper functions available to --format templates.
+var templateFuncs = template.FuncMap{
+	// upper uppercases a string.
+	"upper": strings.ToUpper,
+	// default returns def when value is empty: "", zero, or a nil or
+	// empty pointer. {{default "-" .Suggestion}}
+	"default": func(def string, value any) any {
+		if isEmpty(value) {
+			return def
+		}
+		return deref(value)
+	},
+	// deref returns the value of a pointer field such as .Suggestion, or ""
+	// for nil.
+	"deref": deref,
+	// colorize colors text, or the state itself when there is no text, by
+	// state: {{colorize .State}} or {{colorize .State .Email}}.
+	"colorize": func(state string, text ...string) string {
+		if len(text) == 0 {
+			return stateColorized(state)
+		}
+		return stateColor(state).Sprint(strings.Join(text, " "))
+	},
+}
+
+// ParseTemplate parses a --format template. The escapes \t, \n and \\
+// are expanded first, since shells pass them through literally.
+func ParseTemplate(text string) (*template.Template, error) {
+	text = strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\\`, `\`).Replace(text)
+	return template.New("format").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// PrintTemplate executes a --format template for data and ends the output
+// with a newline.
+func PrintTemplate(w io.Writer, t *template.Template, data any) error {
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return fmt.Errorf("could not format output: %w", err)
+	}
+	b.WriteByte('\n')
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// deref follows pointers, returning "" for nil.
+func deref(value any) any {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	}
+	return v.Interface()
+}
+
+func isEmpty(value any) bool {
+	v := reflect.ValueOf(deref(value))
+	return !v.IsValid() || v.IsZero()
+}
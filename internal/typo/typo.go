@@ -0,0 +1,117 @@
+// Package typo suggests corrections for misspelled domains of popular
+// email providers, such as gmail.com for gmial.con, without calling the
+// API.
+package typo
+
+import "strings"
+
+// Corrector suggests corrections from a list of known domains.
+type Corrector struct {
+	domains []string
+	known   map[string]bool
+}
+
+// New returns a Corrector for a list of known domains, in lowercase ASCII.
+// When two domains are equally close to a misspelling, the one listed
+// first is suggested, so the list should put the most used ones first.
+func New(domains []string) *Corrector {
+	known := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		known[d] = true
+	}
+	return &Corrector{domains: domains, known: known}
+}
+
+// Suggest returns the known domain that domain is most likely a
+// misspelling of. A known domain is never corrected. A domain may differ
+// from a known one of fewer than 9 characters by one edit in its top-level
+// domain, and from a longer one by two edits anywhere, where an edit
+// inserts, deletes or replaces a character, or swaps two adjacent ones.
+func (c *Corrector) Suggest(domain string) (string, bool) {
+	domain = strings.ToLower(domain)
+	if domain == "" || c.known[domain] {
+		return "", false
+	}
+
+	best, bestDist := "", 0
+	for _, d := range c.domains {
+		limit := maxEdits(d)
+		if abs(len(d)-len(domain)) > limit || isShort(d) && !sameName(domain, d) {
+			continue
+		}
+		if dist := Distance(domain, d); dist <= limit && (best == "" || dist < bestDist) {
+			best, bestDist = d, dist
+		}
+	}
+	return best, best != ""
+}
+
+// SuggestAddress returns email with its domain corrected by Suggest.
+func (c *Corrector) SuggestAddress(email string) (string, bool) {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return "", false
+	}
+	domain, ok := c.Suggest(email[at+1:])
+	if !ok {
+		return "", false
+	}
+	return email[:at+1] + domain, true
+}
+
+// maxEdits returns how many edits a misspelling of domain may have.
+func maxEdits(domain string) int {
+	if isShort(domain) {
+		return 1
+	}
+	return 2
+}
+
+// isShort reports whether domain is short enough that a single edit of its
+// name turns it into other real domains, such as aol.com into aon.com.
+// Only a misspelled top-level domain of a short domain is corrected.
+func isShort(domain string) bool {
+	return len(domain) < 9
+}
+
+// sameName reports whether two domains are the same up to their top-level
+// domain.
+func sameName(a, b string) bool {
+	i, j := strings.LastIndexByte(a, '.'), strings.LastIndexByte(b, '.')
+	return i >= 0 && j >= 0 && a[:i] == b[:j]
+}
+
+// Distance returns the number of edits between a and b: characters
+// inserted, deleted or replaced, and adjacent characters swapped (the
+// optimal string alignment distance). It compares bytes, which is enough
+// for ASCII domains.
+func Distance(a, b string) int {
+	// rows[0..2] are the rows for i-2, i-1 and i of the usual table.
+	rows := [3][]int{make([]int, len(b)+1), make([]int, len(b)+1), make([]int, len(b)+1)}
+	for j := range rows[2] {
+		rows[2][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		rows[0], rows[1], rows[2] = rows[1], rows[2], rows[0]
+		prev2, prev, cur := rows[0], rows[1], rows[2]
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+	}
+	return rows[2][len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,107 @@
+package typo
+
+import (
+	"testing"
+
+	"github.com/Truelist-io-Email-Validation/truelist-cli/internal/lists"
+)
+
+func TestSuggest(t *testing.T) {
+	// Use the embedded list, not an updated copy.
+	t.Setenv("HOME", t.TempDir())
+	providers, err := lists.Load(lists.Providers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New(providers.Entries())
+
+	tests := []struct {
+		domain string
+		want   string // "" when there should be no suggestion
+	}{
+		{"gmial.com", "gmail.com"},
+		{"gmial.con", "gmail.com"},
+		{"GMAIL.CON", "gmail.com"},
+		{"gmai.com", "gmail.com"},
+		{"hotmial.com", "hotmail.com"},
+		{"yaho.com", "yahoo.com"},
+		{"outlok.com", "outlook.com"},
+		{"icloud.co", "icloud.com"},
+		{"aol.con", "aol.com"},
+		{"aol.co", "aol.com"},
+		{"me.con", "me.com"},
+
+		// Known domains are never corrected.
+		{"gmail.com", ""},
+		{"email.com", ""},
+		{"mac.com", ""},
+		// Real domains one edit away from a short provider.
+		{"aon.com", ""},
+		{"max.com", ""},
+		{"love.com", ""},
+		{"mc.com", ""},
+		{"aol.org", ""},
+		// Too far from any provider.
+		{"example.com", ""},
+		{"gmxxxx.com", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, ok := c.Suggest(tt.domain)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("Suggest(%q) = %q, %v, want %q", tt.domain, got, ok, tt.want)
+		}
+	}
+}
+
+func TestSuggestPrefersFirstListed(t *testing.T) {
+	c := New([]string{"gmail.com", "gmx.com", "gmail.co"})
+	if got, _ := c.Suggest("gmaxl.com"); got != "gmail.com" {
+		t.Errorf("Suggest(gmaxl.com) = %q, want gmail.com", got)
+	}
+}
+
+func TestSuggestAddress(t *testing.T) {
+	c := New([]string{"gmail.com"})
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"Jo.Smith@gmial.con", "Jo.Smith@gmail.com"},
+		{`"a@b"@gmial.com`, `"a@b"@gmail.com`},
+		{"jo@gmail.com", ""},
+		{"jo@example.com", ""},
+		{"gmial.com", ""},
+	}
+	for _, tt := range tests {
+		got, ok := c.SuggestAddress(tt.email)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("SuggestAddress(%q) = %q, %v, want %q", tt.email, got, ok, tt.want)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"gmail.com", "gmail.com", 0},
+		{"gmial.com", "gmail.com", 1},
+		{"gmai.com", "gmail.com", 1},
+		{"gmail.con", "gmail.com", 1},
+		{"gmial.con", "gmail.com", 2},
+		{"kitten", "sitting", 3},
+		{"ca", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := Distance(tt.b, tt.a); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}